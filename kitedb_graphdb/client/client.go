@@ -0,0 +1,106 @@
+// Package client is a thin Go client for a KiteDB server started with
+// `serve`, talking to its HTTP/JSON gateway.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Client talks to a KiteDB server's HTTP/JSON gateway over addr (e.g.
+// "http://localhost:8080").
+type Client struct {
+	addr       string
+	httpClient *http.Client
+}
+
+// New initializes a Client for the server at addr.
+func New(addr string) *Client {
+	return &Client{
+		addr:       addr,
+		httpClient: &http.Client{},
+	}
+}
+
+// ListDatabases returns the names of every database on the server.
+func (c *Client) ListDatabases() ([]string, error) {
+	resp, err := c.httpClient.Get(c.addr + "/databases")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+
+	var names []string
+	if err := json.NewDecoder(resp.Body).Decode(&names); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return names, nil
+}
+
+// OpenDatabase opens (creating if needed) the named database on the server.
+func (c *Client) OpenDatabase(name string) error {
+	resp, err := c.httpClient.Post(c.addr+"/databases/"+name, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+	return errorFromStatus(resp)
+}
+
+// DropDatabase deletes the named database on the server.
+func (c *Client) DropDatabase(name string) error {
+	req, err := http.NewRequest(http.MethodDelete, c.addr+"/databases/"+name, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %v", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to drop database %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+	return errorFromStatus(resp)
+}
+
+// ExecuteQuery runs a Cypher query against the named database and returns
+// its result rows.
+func (c *Client) ExecuteQuery(name, query string) ([]map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query: %v", err)
+	}
+
+	resp, err := c.httpClient.Post(c.addr+"/databases/"+name+"/query", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorFromStatus(resp)
+	}
+
+	var results []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return results, nil
+}
+
+func errorFromStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Error != "" {
+		return fmt.Errorf("server error: %s", body.Error)
+	}
+	return fmt.Errorf("server returned status %d", resp.StatusCode)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,12 +16,17 @@ type REPL struct {
 	currentDB     *graphdb.GraphDB
 	currentDBName string
 	databases     map[string]*graphdb.GraphDB
+	format        string      // "tree", "json", "bson", or "msgpack"
+	codecName     string      // "compact", "gob", or "proto" -- the codec DB CODEC last set
+	tx            *graphdb.Tx // non-nil while inside a BEGIN/COMMIT/ROLLBACK block
 }
 
 // NewREPL initializes a new REPL
 func NewREPL() *REPL {
 	return &REPL{
 		databases: make(map[string]*graphdb.GraphDB),
+		format:    "tree",
+		codecName: "compact",
 	}
 }
 
@@ -82,28 +88,79 @@ func formatResults(results []map[string]interface{}) string {
 	return output.String()
 }
 
+// parseInterchangeCommand splits the tail of an IMPORT FROM/EXPORT TO
+// command ("'<path>' AS <format>") into its path and format.
+func parseInterchangeCommand(rest string) (path, format string, err error) {
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "'") {
+		return "", "", fmt.Errorf("expected path in single quotes")
+	}
+	end := strings.Index(rest[1:], "'")
+	if end == -1 {
+		return "", "", fmt.Errorf("unterminated path")
+	}
+	path = rest[1 : end+1]
+
+	tail := strings.TrimSpace(rest[end+2:])
+	if !strings.HasPrefix(tail, "AS ") {
+		return "", "", fmt.Errorf("expected 'AS <format>'")
+	}
+	format = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(tail, "AS ")))
+	if format == "" {
+		return "", "", fmt.Errorf("format required")
+	}
+	return path, format, nil
+}
+
 // help displays available commands
 func (r *REPL) help() {
 	fmt.Println(`GraphDB REPL Commands:
   .help                     Show this help message
   .exit                     Exit the REPL
+  .format [tree|json|bson|msgpack]  Show or set the query output format
   CREATE DATABASE <name>    Create a new database
   USE DATABASE <name>       Switch to the specified database
   SHOW DATABASES            List all databases
   DROP DATABASE <name>      Delete the specified database
-  SHOW NODES                List all nodes with Person label
+  SHOW NODES                List all nodes, any label
   SHOW EDGES                List all edges
-  DESCRIBE DATABASE         Show database metadata (Person nodes and edges)
-  CLEAR DATABASE            Delete all Person nodes and edges
+  SHOW LABELS               List all node labels in use
+  SHOW EDGE TYPES           List all relationship types in use
+  DESCRIBE DATABASE         Show per-label node counts and edge count
+  CLEAR DATABASE            Delete all nodes and edges
+  IMPORT FROM '<path>' AS <graphml|csv|ndjson>  Batch-load nodes/edges from a file
+  EXPORT TO '<path>' AS <graphml|csv|ndjson>    Stream all nodes/edges to a file
+  DB STATS                  Show page, cache, and node/edge counts
+  DB COMPACT                Rewrite the file, dropping freed pages
+  DB REINDEX                Rebuild the forward/reverse edge adjacency index
+  DB PAGE <id>              Dump a raw page by ID, for debugging
+  DB CODEC [compact|gob|proto]  Show or set the codec new records are written with
+  EXPLAIN <query>           Print the Graphviz DOT of a query's parsed AST instead of running it
+  BEGIN / BEGIN TRANSACTION  Start a multi-statement transaction
+  COMMIT                    Apply a pending transaction's statements
+  ROLLBACK                  Discard a pending transaction's statements
 Cypher Queries:
   CREATE (n:Person {name: "Alice", age: 30})
   CREATE (n:Person)-[:KNOWS {since: 2020}]->(m:Person)
   MATCH (n:Person) WHERE n.name = "Alice" RETURN n
+  MATCH (n:Person) WHERE n.age >= 21 AND n.age < 65 RETURN n
+  MATCH (n:Person) WHERE n.name =~ "(?i)^al" OR n.city IN ["NYC", "LA"] RETURN n
+  MATCH (n:Person) WHERE NOT n.nickname IS NULL RETURN n
   MATCH ()-[r:KNOWS]->() RETURN r
+  MATCH (a:Person)-[r:KNOWS]->(b:Person) RETURN b
+  MATCH (a:Person)<-[r:KNOWS]-(b:Person) RETURN b
+  MATCH p = (a:User)-[:FRIEND*1..3]->(b:User) RETURN p
+  CREATE (n:Call)-[:ARG@0]->(m:Expr)   Parallel edges of the same type, tagged by ordinal
+  MATCH (n:Call)-[r:ARG@0]->(m:Expr) RETURN m
   MATCH (n:Person) SET n.age = 31
   MATCH (n:Person) DELETE n
   MATCH ()-[r:KNOWS]->() DELETE r
-Type '.exit' or 'quit' to exit.`)
+  CREATE (n:Person)-[:OWNS {_cascade_to: true}]->(m:Pet)
+  MATCH (n:Person) DETACH DELETE n
+Type '.exit' or 'quit' to exit.
+
+Run 'graphdb serve [addr] [dataDir]' instead of the REPL to start a
+network-accessible server exposing the HTTP/JSON gateway.`)
 }
 
 // run executes the REPL loop
@@ -141,6 +198,23 @@ func (r *REPL) run() {
 			continue
 		}
 
+		if strings.HasPrefix(input, ".format") {
+			name := strings.TrimSpace(strings.TrimPrefix(input, ".format"))
+			if name == "" {
+				fmt.Printf("Current format: %s\n", r.format)
+				continue
+			}
+			if name != "tree" {
+				if _, err := graphdb.EncoderFor(name); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					continue
+				}
+			}
+			r.format = name
+			fmt.Printf("Output format set to %s\n", r.format)
+			continue
+		}
+
 		if strings.HasPrefix(input, "CREATE DATABASE ") {
 			name := strings.TrimSpace(strings.TrimPrefix(input, "CREATE DATABASE "))
 			if name == "" {
@@ -248,8 +322,7 @@ func (r *REPL) run() {
 				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
 				continue
 			}
-			fmt.Println("Note: Only nodes with label 'Person' are shown (MATCH (n) not supported)")
-			results, err := r.currentDB.ExecuteQuery("MATCH (n:Person) RETURN n")
+			results, err := r.currentDB.ExecuteQuery("MATCH (n) RETURN n")
 			if err != nil {
 				fmt.Printf("Error: query execution failed: %v\n", err)
 				continue
@@ -259,6 +332,30 @@ func (r *REPL) run() {
 			continue
 		}
 
+		if input == "SHOW LABELS" {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			fmt.Println("Labels:")
+			for _, label := range r.currentDB.Labels() {
+				fmt.Printf("  %s\n", label)
+			}
+			continue
+		}
+
+		if input == "SHOW EDGE TYPES" {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			fmt.Println("Edge types:")
+			for _, edgeType := range r.currentDB.EdgeTypes() {
+				fmt.Printf("  %s\n", edgeType)
+			}
+			continue
+		}
+
 		if input == "SHOW EDGES" {
 			if r.currentDB == nil {
 				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
@@ -279,18 +376,20 @@ func (r *REPL) run() {
 				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
 				continue
 			}
-			nodeResults, err := r.currentDB.ExecuteQuery("MATCH (n:Person) RETURN n")
-			if err != nil {
-				fmt.Printf("Error: failed to fetch nodes: %v\n", err)
-				continue
+			fmt.Printf("Database %s:\n", r.currentDBName)
+			for _, label := range r.currentDB.Labels() {
+				results, err := r.currentDB.ExecuteQuery(fmt.Sprintf("MATCH (n:%s) RETURN n", label))
+				if err != nil {
+					fmt.Printf("Error: failed to fetch nodes labeled %s: %v\n", label, err)
+					continue
+				}
+				fmt.Printf("  %s: %d\n", label, len(results))
 			}
 			edgeResults, err := r.currentDB.ExecuteQuery("MATCH ()-[r]->() RETURN r")
 			if err != nil {
 				fmt.Printf("Error: failed to fetch edges: %v\n", err)
 				continue
 			}
-			fmt.Printf("Database %s:\n", r.currentDBName)
-			fmt.Printf("  Nodes (Person label): %d\n", len(nodeResults))
 			fmt.Printf("  Edges: %d\n", len(edgeResults))
 			continue
 		}
@@ -300,7 +399,7 @@ func (r *REPL) run() {
 				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
 				continue
 			}
-			_, err := r.currentDB.ExecuteQuery("MATCH (n:Person) DELETE n")
+			_, err := r.currentDB.ExecuteQuery("MATCH (n) DELETE n")
 			if err != nil {
 				fmt.Printf("Error: failed to delete nodes: %v\n", err)
 				continue
@@ -314,12 +413,247 @@ func (r *REPL) run() {
 			continue
 		}
 
+		if strings.HasPrefix(input, "IMPORT FROM ") {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			path, format, err := parseInterchangeCommand(strings.TrimPrefix(input, "IMPORT FROM "))
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			importer, err := graphdb.ImporterFor(format)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				fmt.Printf("Error: failed to open %s: %v\n", path, err)
+				continue
+			}
+			err = importer.Import(r.currentDB, f)
+			f.Close()
+			if err != nil {
+				fmt.Printf("Error: import failed: %v\n", err)
+				continue
+			}
+			fmt.Printf("Imported %s as %s\n", path, format)
+			continue
+		}
+
+		if strings.HasPrefix(input, "EXPORT TO ") {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			path, format, err := parseInterchangeCommand(strings.TrimPrefix(input, "EXPORT TO "))
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			exporter, err := graphdb.ExporterFor(format)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			f, err := os.Create(path)
+			if err != nil {
+				fmt.Printf("Error: failed to create %s: %v\n", path, err)
+				continue
+			}
+			err = exporter.Export(r.currentDB, f)
+			f.Close()
+			if err != nil {
+				fmt.Printf("Error: export failed: %v\n", err)
+				continue
+			}
+			fmt.Printf("Exported to %s as %s\n", path, format)
+			continue
+		}
+
+		if input == "BEGIN" || input == "BEGIN TRANSACTION" {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			if r.tx != nil {
+				fmt.Println("Error: a transaction is already in progress")
+				continue
+			}
+			tx, err := r.currentDB.Begin()
+			if err != nil {
+				fmt.Printf("Error: failed to begin transaction: %v\n", err)
+				continue
+			}
+			r.tx = tx
+			fmt.Println("Transaction started")
+			continue
+		}
+
+		if input == "COMMIT" {
+			if r.tx == nil {
+				fmt.Println("Error: no transaction in progress")
+				continue
+			}
+			err := r.tx.Commit()
+			r.tx = nil
+			if err != nil {
+				fmt.Printf("Error: commit failed: %v\n", err)
+				continue
+			}
+			fmt.Println("Transaction committed")
+			continue
+		}
+
+		if input == "ROLLBACK" {
+			if r.tx == nil {
+				fmt.Println("Error: no transaction in progress")
+				continue
+			}
+			err := r.tx.Rollback()
+			r.tx = nil
+			if err != nil {
+				fmt.Printf("Error: rollback failed: %v\n", err)
+				continue
+			}
+			fmt.Println("Transaction rolled back")
+			continue
+		}
+
+		if input == "DB STATS" {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			stats, err := r.currentDB.Stats()
+			if err != nil {
+				fmt.Printf("Error: failed to gather stats: %v\n", err)
+				continue
+			}
+			fmt.Printf("Pages: %d (free: %d)\n", stats.PageCount, stats.FreePageCount)
+			fmt.Printf("Cache: %d hits, %d misses (%.1f%% hit ratio)\n", stats.CacheHits, stats.CacheMisses, stats.CacheHitRatio*100)
+			fmt.Printf("Nodes: %d %v\n", stats.NodeCount, stats.NodesByLabel)
+			fmt.Printf("Edges: %d %v\n", stats.EdgeCount, stats.EdgesByType)
+			continue
+		}
+
+		if input == "DB COMPACT" {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			if err := r.currentDB.Compact(); err != nil {
+				fmt.Printf("Error: compaction failed: %v\n", err)
+				continue
+			}
+			fmt.Println("Database compacted")
+			continue
+		}
+
+		if input == "DB REINDEX" {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			if err := r.currentDB.RebuildAdjacencyIndex(); err != nil {
+				fmt.Printf("Error: reindex failed: %v\n", err)
+				continue
+			}
+			fmt.Println("Adjacency index rebuilt")
+			continue
+		}
+
+		if strings.HasPrefix(input, "DB CODEC") {
+			name := strings.TrimSpace(strings.TrimPrefix(input, "DB CODEC"))
+			if name == "" {
+				fmt.Printf("Current codec: %s\n", r.codecName)
+				continue
+			}
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			codec, err := graphdb.CodecFor(name)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			r.currentDB.SetRecordCodec(codec)
+			r.codecName = name
+			fmt.Printf("Codec set to %s for new records\n", r.codecName)
+			continue
+		}
+
+		if strings.HasPrefix(input, "EXPLAIN ") {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			dot, err := r.currentDB.ExplainQuery(strings.TrimPrefix(input, "EXPLAIN "))
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Println(dot)
+			continue
+		}
+
+		if strings.HasPrefix(input, "DB PAGE ") {
+			if r.currentDB == nil {
+				fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
+				continue
+			}
+			var pageID int
+			if _, err := fmt.Sscanf(strings.TrimPrefix(input, "DB PAGE "), "%d", &pageID); err != nil {
+				fmt.Println("Error: usage: DB PAGE <id>")
+				continue
+			}
+			data, err := r.currentDB.GetPage(pageID)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Page %d (%d bytes):\n%x\n", pageID, len(data), data)
+			continue
+		}
+
 		// Handle Cypher queries
 		if r.currentDB == nil {
 			fmt.Println("Error: no database selected; use 'USE DATABASE <name>'")
 			continue
 		}
 
+		// Inside a BEGIN block, statements run against the pending
+		// transaction instead of committing individually.
+		if r.tx != nil {
+			results, err := r.tx.ExecuteQuery(input)
+			if err != nil {
+				fmt.Printf("Error: query execution failed: %v\n", err)
+				continue
+			}
+			fmt.Println("Query Successful")
+			fmt.Println(formatResults(results))
+			continue
+		}
+
+		if r.format != "tree" {
+			enc, err := graphdb.EncoderFor(r.format)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			data, err := r.currentDB.ExecuteQueryEncoded(input, enc)
+			if err != nil {
+				fmt.Printf("Error: query execution failed: %v\n", err)
+				continue
+			}
+			fmt.Println("Query Successful")
+			fmt.Printf("%q\n", data)
+			continue
+		}
+
 		results, err := r.currentDB.ExecuteQuery(input)
 		if err != nil {
 			fmt.Printf("Error: query execution failed: %v\n", err)
@@ -334,7 +668,33 @@ func (r *REPL) run() {
 	}
 }
 
+// serve starts a multi-tenant database daemon listening on addr, exposing
+// every database under dataDir over the HTTP/JSON gateway.
+func serve(addr, dataDir string) {
+	server := graphdb.NewServer(dataDir)
+	defer server.Close()
+
+	fmt.Printf("KiteDB server listening on %s (data dir: %s)\n", addr, dataDir)
+	if err := http.ListenAndServe(addr, graphdb.NewHTTPGateway(server)); err != nil {
+		fmt.Printf("Error: server stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		addr := ":8080"
+		dataDir := "databases"
+		if len(os.Args) > 2 {
+			addr = os.Args[2]
+		}
+		if len(os.Args) > 3 {
+			dataDir = os.Args[3]
+		}
+		serve(addr, dataDir)
+		return
+	}
+
 	repl := NewREPL()
 	repl.run()
 }
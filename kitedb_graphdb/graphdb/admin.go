@@ -0,0 +1,182 @@
+package graphdb
+
+import (
+	"fmt"
+	"os"
+)
+
+// DBStats reports low-level operator-facing statistics, analogous to
+// geth's `db.stats`.
+type DBStats struct {
+	PageCount      int
+	FreePageCount  int
+	CacheHits      int64
+	CacheMisses    int64
+	CacheHitRatio  float64
+	CacheEvictions int64
+	CacheDirty     int
+	NodeCount      int
+	EdgeCount      int
+	NodesByLabel   map[string]int
+	EdgesByType    map[string]int
+}
+
+// Stats gathers page, cache, and per-label/type counts for the database.
+func (db *GraphDB) Stats() (DBStats, error) {
+	livePages := db.livePageSet()
+
+	bpStats := db.bufferPool.Stats()
+	stats := DBStats{
+		PageCount:      db.storage.PageCount(),
+		FreePageCount:  db.storage.PageCount() - 1 - len(livePages),
+		CacheHits:      bpStats.Hits,
+		CacheMisses:    bpStats.Misses,
+		CacheHitRatio:  bpStats.HitRatio(),
+		CacheEvictions: bpStats.Evictions,
+		CacheDirty:     bpStats.Dirty,
+		NodesByLabel:   make(map[string]int),
+		EdgesByType:    make(map[string]int),
+	}
+
+	for label, ids := range db.graph.nodeLabelMap {
+		stats.NodesByLabel[label] = len(ids)
+		stats.NodeCount += len(ids)
+	}
+
+	for _, edgeID := range db.indexMgr.GetEdgeIDs() {
+		edge, err := db.graph.GetEdge(edgeID)
+		if err != nil {
+			continue
+		}
+		stats.EdgeCount++
+		stats.EdgesByType[edge.Type]++
+	}
+
+	return stats, nil
+}
+
+// livePageSet returns the set of page IDs still reachable from the node
+// and edge indexes, i.e. everything Compact must preserve. Several
+// RecordIDs can share a page now that records are packed via a slot
+// directory, so the page only needs to be kept once.
+func (db *GraphDB) livePageSet() map[int]bool {
+	live := make(map[int]bool)
+	for _, id := range db.indexMgr.nodeIndex {
+		live[id.PageID] = true
+	}
+	for _, id := range db.indexMgr.edgeIndex {
+		live[id.PageID] = true
+	}
+	return live
+}
+
+// Compact rewrites the underlying file, dropping every page not reachable
+// from the node/edge indexes. This reclaims whole pages left orphaned by
+// UpdateNode/UpdateEdge/DeleteNode/DeleteEdge's old records once every
+// slot on them has been tombstoned; RecordManager.DeleteRecord already
+// reclaims individual tombstoned slots within a still-live page via
+// slotDirectory.compact, so Compact only needs to deal in whole pages.
+func (db *GraphDB) Compact() error {
+	// copyPage below reads pages straight off db.storage, bypassing
+	// db.bufferPool's cache entirely, so any page only dirty in the pool
+	// must reach disk first or Compact would copy its stale, pre-write
+	// contents.
+	if err := db.bufferPool.FlushAll(); err != nil {
+		return fmt.Errorf("failed to flush buffer pool before compaction: %v", err)
+	}
+
+	tmpFilename := db.storage.filename + ".compact"
+	tmp, err := NewStorageManager(tmpFilename, db.storage.pageSize)
+	if err != nil {
+		return fmt.Errorf("failed to create compaction file: %v", err)
+	}
+
+	remap := make(map[int]int) // old pageID -> new pageID
+	copyPage := func(oldPageID int) (int, error) {
+		if newPageID, ok := remap[oldPageID]; ok {
+			return newPageID, nil
+		}
+		data, err := db.storage.ReadPage(oldPageID)
+		if err != nil {
+			return -1, fmt.Errorf("failed to read page %d: %v", oldPageID, err)
+		}
+		newPageID, err := tmp.AllocatePage()
+		if err != nil {
+			return -1, fmt.Errorf("failed to allocate compacted page: %v", err)
+		}
+		if err := tmp.WritePage(newPageID, data); err != nil {
+			return -1, fmt.Errorf("failed to write compacted page %d: %v", newPageID, err)
+		}
+		remap[oldPageID] = newPageID
+		return newPageID, nil
+	}
+
+	for nodeID, oldID := range db.indexMgr.nodeIndex {
+		newPageID, err := copyPage(oldID.PageID)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpFilename)
+			return err
+		}
+		db.indexMgr.nodeIndex[nodeID] = RecordID{PageID: newPageID, SlotID: oldID.SlotID}
+	}
+	for edgeID, oldID := range db.indexMgr.edgeIndex {
+		newPageID, err := copyPage(oldID.PageID)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpFilename)
+			return err
+		}
+		db.indexMgr.edgeIndex[edgeID] = RecordID{PageID: newPageID, SlotID: oldID.SlotID}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close compaction file: %v", err)
+	}
+	if err := db.storage.Close(); err != nil {
+		return fmt.Errorf("failed to close original file: %v", err)
+	}
+	if err := os.Rename(tmpFilename, db.storage.filename); err != nil {
+		return fmt.Errorf("failed to replace database file: %v", err)
+	}
+
+	reopened, err := NewStorageManager(db.storage.filename, db.storage.pageSize)
+	if err != nil {
+		return fmt.Errorf("failed to reopen compacted database: %v", err)
+	}
+	db.storage = reopened
+	db.bufferPool.storage = reopened
+	// The page RecordManager was packing new records onto no longer
+	// exists under that ID in the compacted file.
+	db.recordMgr.activePage = -1
+	return db.bufferPool.Close()
+}
+
+// GetPage reads a raw page by ID, for debugging corrupted stores.
+func (db *GraphDB) GetPage(pageID int) ([]byte, error) {
+	data, err := db.bufferPool.GetPage(pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %v", pageID, err)
+	}
+	return data, nil
+}
+
+// PutPage overwrites a raw page by ID, for debugging corrupted stores.
+// data must be exactly the database's page size.
+func (db *GraphDB) PutPage(pageID int, data []byte) error {
+	if err := db.bufferPool.WritePage(pageID, data); err != nil {
+		return fmt.Errorf("failed to write page %d: %v", pageID, err)
+	}
+	return nil
+}
+
+// DeletePage zeroes a raw page by ID, for debugging corrupted stores. It
+// does not update the node/edge indexes, so callers are responsible for
+// leaving the database in a consistent state.
+func (db *GraphDB) DeletePage(pageID int) error {
+	zeroed := make([]byte, db.storage.pageSize)
+	if err := db.bufferPool.WritePage(pageID, zeroed); err != nil {
+		return fmt.Errorf("failed to delete page %d: %v", pageID, err)
+	}
+	return nil
+}
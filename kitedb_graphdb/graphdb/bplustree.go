@@ -0,0 +1,356 @@
+package graphdb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Index is a secondary index over node IDs keyed by a property value (or,
+// for a composite index, a []interface{} tuple of several). BPlusTreeIndex
+// is the only implementation today; the interface exists so IndexManager's
+// property indexes aren't hard-wired to one data structure.
+type Index interface {
+	Insert(key interface{}, nodeID int64) error
+	Delete(key interface{}, nodeID int64) error
+	Seek(lo, hi interface{}) []int64
+	Prefix(prefix string) []int64
+}
+
+// compareKeys orders two index keys, matching the type set Property.Value
+// holds (int64/float64/string/bool) plus []interface{} for composite index
+// keys, compared element-wise. Property types aren't enforced consistently
+// across nodes sharing a label, so mismatched dynamic types fall back to a
+// deterministic (if arbitrary) order by type name rather than panicking.
+func compareKeys(a, b interface{}) int {
+	switch av := a.(type) {
+	case int64:
+		if bv, ok := b.(int64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			default:
+				return 0
+			}
+		}
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			default:
+				return 0
+			}
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av && bv:
+				return -1
+			default:
+				return 1
+			}
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			for i := range av {
+				if i >= len(bv) {
+					return 1
+				}
+				if c := compareKeys(av[i], bv[i]); c != 0 {
+					return c
+				}
+			}
+			if len(bv) > len(av) {
+				return -1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b))
+}
+
+// bptEntry is one leaf entry: a key and every node ID currently holding it
+// (more than one is legal unless the index is unique).
+type bptEntry struct {
+	key     interface{}
+	nodeIDs []int64
+}
+
+// bptNode is a single B+tree node. Leaves hold entries in key order plus a
+// sibling pointer so Seek/Prefix can walk a range without re-descending the
+// tree; internal nodes hold separator keys and child pointers, with
+// len(children) == len(keys)+1. A separator key always equals the smallest
+// key reachable through the child to its right.
+type bptNode struct {
+	leaf     bool
+	keys     []interface{}
+	entries  []bptEntry
+	children []*bptNode
+	next     *bptNode
+}
+
+// pathStep is one (internal node, child index) hop taken while descending
+// to a leaf, kept so a split can walk back up and insert a separator into
+// each ancestor without the nodes needing parent pointers.
+type pathStep struct {
+	node *bptNode
+	idx  int
+}
+
+// BPlusTreeIndex is a secondary index over node IDs, keyed by a property
+// value. It supports equality/range lookups (Seek) and prefix lookups
+// (Prefix) in better than the O(n) a full node-label scan costs.
+type BPlusTreeIndex struct {
+	root   *bptNode
+	fanout int
+	unique bool
+}
+
+// NewBPlusTreeIndex creates an empty index. fanout bounds how many entries
+// a leaf (or children an internal node) holds before splitting; a smaller
+// value exercises splitting sooner but every value is correct regardless,
+// it's purely a speed/memory tradeoff. unique makes Insert reject a key
+// already held by a different node ID.
+func NewBPlusTreeIndex(fanout int, unique bool) *BPlusTreeIndex {
+	if fanout < 3 {
+		fanout = 3
+	}
+	return &BPlusTreeIndex{
+		root:   &bptNode{leaf: true},
+		fanout: fanout,
+		unique: unique,
+	}
+}
+
+// descend returns the leaf that holds (or would hold) key, along with the
+// root-to-leaf path of internal hops taken to reach it.
+func (t *BPlusTreeIndex) descend(key interface{}) (*bptNode, []pathStep) {
+	var path []pathStep
+	node := t.root
+	for !node.leaf {
+		i := 0
+		for i < len(node.keys) && compareKeys(key, node.keys[i]) >= 0 {
+			i++
+		}
+		path = append(path, pathStep{node: node, idx: i})
+		node = node.children[i]
+	}
+	return node, path
+}
+
+// Insert adds nodeID under key, appending to an existing entry's ID list
+// if key is already present. It rejects the insert if the index is unique
+// and key already belongs to a different node ID.
+func (t *BPlusTreeIndex) Insert(key interface{}, nodeID int64) error {
+	leaf, path := t.descend(key)
+
+	idx := 0
+	for idx < len(leaf.keys) && compareKeys(leaf.keys[idx], key) < 0 {
+		idx++
+	}
+	if idx < len(leaf.keys) && compareKeys(leaf.keys[idx], key) == 0 {
+		entry := &leaf.entries[idx]
+		if t.unique && len(entry.nodeIDs) > 0 && !containsID(entry.nodeIDs, nodeID) {
+			return fmt.Errorf("unique index violation for key %v", key)
+		}
+		if !containsID(entry.nodeIDs, nodeID) {
+			entry.nodeIDs = append(entry.nodeIDs, nodeID)
+		}
+		return nil
+	}
+
+	leaf.keys = append(leaf.keys, nil)
+	copy(leaf.keys[idx+1:], leaf.keys[idx:])
+	leaf.keys[idx] = key
+
+	leaf.entries = append(leaf.entries, bptEntry{})
+	copy(leaf.entries[idx+1:], leaf.entries[idx:])
+	leaf.entries[idx] = bptEntry{key: key, nodeIDs: []int64{nodeID}}
+
+	if len(leaf.keys) > t.fanout {
+		t.splitLeaf(leaf, path)
+	}
+	return nil
+}
+
+// splitLeaf halves leaf's entries into a new right-hand leaf, links it in
+// via next, and promotes its first key as the separator in leaf's parent.
+func (t *BPlusTreeIndex) splitLeaf(leaf *bptNode, path []pathStep) {
+	mid := len(leaf.keys) / 2
+	right := &bptNode{
+		leaf:    true,
+		keys:    append([]interface{}{}, leaf.keys[mid:]...),
+		entries: append([]bptEntry{}, leaf.entries[mid:]...),
+		next:    leaf.next,
+	}
+	leaf.keys = leaf.keys[:mid]
+	leaf.entries = leaf.entries[:mid]
+	leaf.next = right
+
+	t.insertIntoParent(leaf, right.keys[0], right, path)
+}
+
+// insertIntoParent inserts (sepKey, right) as left's new right sibling in
+// the parent named by the last step of path, splitting (and recursing
+// further up) if the parent overflows. If path is empty, left was the
+// root and a new root is created over both halves.
+func (t *BPlusTreeIndex) insertIntoParent(left *bptNode, sepKey interface{}, right *bptNode, path []pathStep) {
+	if len(path) == 0 {
+		t.root = &bptNode{
+			leaf:     false,
+			keys:     []interface{}{sepKey},
+			children: []*bptNode{left, right},
+		}
+		return
+	}
+
+	step := path[len(path)-1]
+	parent := step.node
+	i := step.idx // left == parent.children[i]
+
+	parent.keys = append(parent.keys, nil)
+	copy(parent.keys[i+1:], parent.keys[i:])
+	parent.keys[i] = sepKey
+
+	parent.children = append(parent.children, nil)
+	copy(parent.children[i+2:], parent.children[i+1:])
+	parent.children[i+1] = right
+
+	if len(parent.keys) > t.fanout {
+		t.splitInternal(parent, path[:len(path)-1])
+	}
+}
+
+// splitInternal halves node's children into a new right-hand internal
+// node, promoting the middle key (not copied into either half, unlike a
+// leaf split) as the separator in node's parent.
+func (t *BPlusTreeIndex) splitInternal(node *bptNode, path []pathStep) {
+	mid := len(node.keys) / 2
+	sepKey := node.keys[mid]
+
+	right := &bptNode{
+		leaf:     false,
+		keys:     append([]interface{}{}, node.keys[mid+1:]...),
+		children: append([]*bptNode{}, node.children[mid+1:]...),
+	}
+	node.keys = node.keys[:mid]
+	node.children = node.children[:mid+1]
+
+	t.insertIntoParent(node, sepKey, right, path)
+}
+
+// Delete removes nodeID from key's entry, dropping the entry entirely once
+// its ID list is empty. It never merges or rebalances underflowed nodes
+// back together -- a deliberate simplification: the fan-out bound only
+// needs to cap how large a single node grows, not keep the tree maximally
+// compact, so skipping merge-on-delete trades a little extra memory for
+// much simpler deletion logic.
+func (t *BPlusTreeIndex) Delete(key interface{}, nodeID int64) error {
+	leaf, _ := t.descend(key)
+	idx := 0
+	for idx < len(leaf.keys) && compareKeys(leaf.keys[idx], key) < 0 {
+		idx++
+	}
+	if idx >= len(leaf.keys) || compareKeys(leaf.keys[idx], key) != 0 {
+		return fmt.Errorf("key %v not found in index", key)
+	}
+	entry := &leaf.entries[idx]
+	entry.nodeIDs = removeID(entry.nodeIDs, nodeID)
+	if len(entry.nodeIDs) == 0 {
+		leaf.keys = append(leaf.keys[:idx], leaf.keys[idx+1:]...)
+		leaf.entries = append(leaf.entries[:idx], leaf.entries[idx+1:]...)
+	}
+	return nil
+}
+
+// Seek returns the node IDs whose key falls in [lo, hi], either bound
+// being nil for unbounded. It descends once to the leaf containing lo (or
+// the whole tree's first leaf if lo is nil), then walks sibling pointers,
+// so an arbitrarily wide range costs one descent plus a linear scan of the
+// matching leaves rather than a full-index scan.
+func (t *BPlusTreeIndex) Seek(lo, hi interface{}) []int64 {
+	var leaf *bptNode
+	if lo != nil {
+		leaf, _ = t.descend(lo)
+	} else {
+		leaf = t.leftmostLeaf()
+	}
+
+	var ids []int64
+	for leaf != nil {
+		for i, k := range leaf.keys {
+			if lo != nil && compareKeys(k, lo) < 0 {
+				continue
+			}
+			if hi != nil && compareKeys(k, hi) > 0 {
+				return ids
+			}
+			ids = append(ids, leaf.entries[i].nodeIDs...)
+		}
+		leaf = leaf.next
+	}
+	return ids
+}
+
+// Prefix returns the node IDs of every string key starting with prefix.
+// Since matching keys sort into the contiguous range [prefix, prefix one
+// character past its last byte), it descends once to where prefix would
+// sit and walks forward until a key no longer matches.
+func (t *BPlusTreeIndex) Prefix(prefix string) []int64 {
+	leaf, _ := t.descend(prefix)
+	var ids []int64
+	for leaf != nil {
+		for i, k := range leaf.keys {
+			s, ok := k.(string)
+			if !ok {
+				continue
+			}
+			if s < prefix {
+				continue
+			}
+			if !strings.HasPrefix(s, prefix) {
+				return ids
+			}
+			ids = append(ids, leaf.entries[i].nodeIDs...)
+		}
+		leaf = leaf.next
+	}
+	return ids
+}
+
+func (t *BPlusTreeIndex) leftmostLeaf() *bptNode {
+	node := t.root
+	for !node.leaf {
+		node = node.children[0]
+	}
+	return node
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeID(ids []int64, id int64) []int64 {
+	out := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
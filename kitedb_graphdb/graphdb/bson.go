@@ -0,0 +1,203 @@
+package graphdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// BSONEncoder renders results as a BSON document array: `{"0": row0, "1":
+// row1, ...}`, the conventional BSON encoding of a top-level list. Each
+// node/edge is tagged with a "_kind" discriminator ("node" or "edge") so a
+// client can round-trip them without guessing from shape, and every
+// PropertyType is rendered as its natural BSON type -- see
+// bsonAppendValue.
+type BSONEncoder struct{}
+
+// Encode implements Encoder.
+func (BSONEncoder) Encode(results []map[string]interface{}) ([]byte, error) {
+	doc := newBSONDoc()
+	for i, row := range results {
+		rowDoc, err := bsonEncodeRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result row %d: %v", i, err)
+		}
+		doc.appendDocument(fmt.Sprintf("%d", i), rowDoc)
+	}
+	return doc.bytes(), nil
+}
+
+// bsonEncodeRow encodes a single result row (varName -> node/edge map).
+func bsonEncodeRow(row map[string]interface{}) (*bsonDoc, error) {
+	doc := newBSONDoc()
+	for varName, item := range row {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected result shape for %q", varName)
+		}
+
+		itemDoc := newBSONDoc()
+		if _, isEdge := itemMap["type"]; isEdge {
+			itemDoc.appendString("_kind", "edge")
+			itemDoc.appendInt64("id", itemMap["id"].(int64))
+			itemDoc.appendString("type", itemMap["type"].(string))
+			itemDoc.appendInt64("source", itemMap["source"].(int64))
+			itemDoc.appendInt64("target", itemMap["target"].(int64))
+		} else {
+			itemDoc.appendString("_kind", "node")
+			itemDoc.appendInt64("id", itemMap["id"].(int64))
+			labels, _ := itemMap["labels"].([]string)
+			labelArr := newBSONDoc()
+			for i, label := range labels {
+				labelArr.appendString(fmt.Sprintf("%d", i), label)
+			}
+			itemDoc.appendArray("labels", labelArr)
+		}
+
+		props, _ := itemMap["properties"].([]Property)
+		propArr := newBSONDoc()
+		for i, prop := range props {
+			propDoc := newBSONDoc()
+			propDoc.appendString("key", prop.Key)
+			if err := bsonAppendValue(propDoc, "value", prop.Value); err != nil {
+				return nil, fmt.Errorf("property %q: %v", prop.Key, err)
+			}
+			propArr.appendDocument(fmt.Sprintf("%d", i), propDoc)
+		}
+		itemDoc.appendArray("properties", propArr)
+
+		doc.appendDocument(varName, itemDoc)
+	}
+	return doc, nil
+}
+
+// bsonAppendValue appends value under name, dispatching on value's
+// concrete Go type rather than a PropertyType tag so it also covers
+// PropertyList/PropertyMap elements, which carry no tag of their own (see
+// readTaggedValue in utils.go) -- every type inferPropertyType recognizes
+// has a case here.
+func bsonAppendValue(doc *bsonDoc, name string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		doc.appendNull(name)
+	case int64:
+		doc.appendInt64(name, v)
+	case string:
+		doc.appendString(name, v)
+	case bool:
+		doc.appendBool(name, v)
+	case float64:
+		doc.appendDouble(name, v)
+	case time.Time:
+		doc.appendDatetime(name, v)
+	case []byte:
+		doc.appendBinary(name, v)
+	case []interface{}:
+		arr := newBSONDoc()
+		for i, elem := range v {
+			if err := bsonAppendValue(arr, fmt.Sprintf("%d", i), elem); err != nil {
+				return err
+			}
+		}
+		doc.appendArray(name, arr)
+	case map[string]interface{}:
+		sub := newBSONDoc()
+		for k, elem := range v {
+			if err := bsonAppendValue(sub, k, elem); err != nil {
+				return err
+			}
+		}
+		doc.appendDocument(name, sub)
+	default:
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+	return nil
+}
+
+// bsonDoc incrementally builds a BSON document body (the elements between
+// the length prefix and trailing NUL).
+type bsonDoc struct {
+	buf bytes.Buffer
+}
+
+func newBSONDoc() *bsonDoc {
+	return &bsonDoc{}
+}
+
+func (d *bsonDoc) appendString(name, value string) {
+	d.buf.WriteByte(0x02) // string
+	writeCString(&d.buf, name)
+	binary.Write(&d.buf, binary.LittleEndian, int32(len(value)+1))
+	d.buf.WriteString(value)
+	d.buf.WriteByte(0x00)
+}
+
+func (d *bsonDoc) appendInt64(name string, value int64) {
+	d.buf.WriteByte(0x12) // int64
+	writeCString(&d.buf, name)
+	binary.Write(&d.buf, binary.LittleEndian, value)
+}
+
+func (d *bsonDoc) appendBool(name string, value bool) {
+	d.buf.WriteByte(0x08) // boolean
+	writeCString(&d.buf, name)
+	if value {
+		d.buf.WriteByte(0x01)
+	} else {
+		d.buf.WriteByte(0x00)
+	}
+}
+
+func (d *bsonDoc) appendDouble(name string, value float64) {
+	d.buf.WriteByte(0x01) // double
+	writeCString(&d.buf, name)
+	binary.Write(&d.buf, binary.LittleEndian, value)
+}
+
+func (d *bsonDoc) appendBinary(name string, value []byte) {
+	d.buf.WriteByte(0x05) // binary
+	writeCString(&d.buf, name)
+	binary.Write(&d.buf, binary.LittleEndian, int32(len(value)))
+	d.buf.WriteByte(0x00) // generic binary subtype
+	d.buf.Write(value)
+}
+
+func (d *bsonDoc) appendDatetime(name string, value time.Time) {
+	d.buf.WriteByte(0x09) // UTC datetime
+	writeCString(&d.buf, name)
+	binary.Write(&d.buf, binary.LittleEndian, value.UnixMilli())
+}
+
+func (d *bsonDoc) appendNull(name string) {
+	d.buf.WriteByte(0x0A) // null
+	writeCString(&d.buf, name)
+}
+
+func (d *bsonDoc) appendDocument(name string, child *bsonDoc) {
+	d.buf.WriteByte(0x03) // embedded document
+	writeCString(&d.buf, name)
+	d.buf.Write(child.bytes())
+}
+
+func (d *bsonDoc) appendArray(name string, child *bsonDoc) {
+	d.buf.WriteByte(0x04) // array (same wire shape as a document)
+	writeCString(&d.buf, name)
+	d.buf.Write(child.bytes())
+}
+
+// bytes renders the complete BSON document: int32 length prefix, element
+// bytes, trailing NUL.
+func (d *bsonDoc) bytes() []byte {
+	var out bytes.Buffer
+	total := int32(4 + d.buf.Len() + 1)
+	binary.Write(&out, binary.LittleEndian, total)
+	out.Write(d.buf.Bytes())
+	out.WriteByte(0x00)
+	return out.Bytes()
+}
+
+func writeCString(buf *bytes.Buffer, s string) {
+	buf.WriteString(s)
+	buf.WriteByte(0x00)
+}
@@ -1,17 +1,45 @@
 package graphdb
 
 import (
-	"container/list"
 	"fmt"
+	"sync"
 )
 
-// BufferPool manages a cache of pages in memory
+// Frame is one cached page slot. pinCount and dirty let callers hold a page
+// across a read-modify-write without an eviction racing underneath them;
+// refBit is CLOCK's "has this been touched since we last swept past it"
+// bit.
+type Frame struct {
+	pageID   int
+	data     []byte
+	pinCount int32
+	dirty    bool
+	refBit   bool
+}
+
+// BufferPool manages a cache of pages in memory. It's safe for concurrent
+// use: every method takes mu, which also guards the CLOCK sweep so an
+// eviction never runs concurrently with a pin or a write to the same
+// frame.
+//
+// WritePage defers the actual disk write: it marks the frame dirty and
+// only flushes it when CLOCK evicts it or FlushAll runs (as Checkpoint
+// and Close both do) -- unlike the
+// earlier write-through BufferPool, a page written and then overwritten
+// again before eviction now costs one disk write instead of two.
 type BufferPool struct {
+	mu       sync.Mutex
 	storage  *StorageManager
 	capacity int
-	pages    map[int][]byte
-	lru      *list.List
-	lruKeys  map[int]*list.Element
+	frames   map[int]*Frame
+	// clock holds every cached frame in CLOCK's circular sweep order;
+	// hand is the index the next sweep starts from. Evicting a frame
+	// removes it from the slice rather than leaving a tombstone, so the
+	// slice's length always equals len(frames).
+	clock []*Frame
+	hand  int
+
+	hits, misses, evictions, dirtyFlushes int64
 }
 
 // NewBufferPool initializes a new BufferPool
@@ -19,89 +47,236 @@ func NewBufferPool(storage *StorageManager, capacity int) *BufferPool {
 	return &BufferPool{
 		storage:  storage,
 		capacity: capacity,
-		pages:    make(map[int][]byte),
-		lru:      list.New(),
-		lruKeys:  make(map[int]*list.Element),
+		frames:   make(map[int]*Frame),
 	}
 }
 
-// GetPage retrieves a page, loading from disk if not in cache
-func (bp *BufferPool) GetPage(pageID int) ([]byte, error) {
+// BufferPoolStats reports cache effectiveness for `DB STATS`.
+type BufferPoolStats struct {
+	Hits         int64
+	Misses       int64
+	Evictions    int64
+	DirtyFlushes int64
+	Cached       int
+	Dirty        int
+	Capacity     int
+}
+
+// HitRatio returns the fraction of GetPage calls served from cache.
+func (s BufferPoolStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
 
-	// Check if page is in cache
-	if data, ok := bp.pages[pageID]; ok {
-		// Update LRU
-		if elem, exists := bp.lruKeys[pageID]; exists {
-			bp.lru.MoveToFront(elem)
+// Stats reports the buffer pool's current cache hit/miss/eviction/dirty
+// counters.
+func (bp *BufferPool) Stats() BufferPoolStats {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	dirty := 0
+	for _, f := range bp.frames {
+		if f.dirty {
+			dirty++
 		}
-		return data, nil
 	}
+	return BufferPoolStats{
+		Hits:         bp.hits,
+		Misses:       bp.misses,
+		Evictions:    bp.evictions,
+		DirtyFlushes: bp.dirtyFlushes,
+		Cached:       len(bp.frames),
+		Dirty:        dirty,
+		Capacity:     bp.capacity,
+	}
+}
+
+// GetPage retrieves a page, loading from disk if not in cache. The
+// returned slice is the frame's live buffer, exactly like the old
+// write-through pool returned -- callers that mutate it in place and then
+// call WritePage keep working unchanged.
+func (bp *BufferPool) GetPage(pageID int) ([]byte, error) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if f, ok := bp.frames[pageID]; ok {
+		bp.hits++
+		f.refBit = true
+		return f.data, nil
+	}
+	bp.misses++
 
-	// Load page from disk
 	data, err := bp.storage.ReadPage(pageID)
 	if err != nil {
 		return nil, err
 	}
+	f, err := bp.frameFor(pageID, data, false)
+	if err != nil {
+		return nil, err
+	}
+	return f.data, nil
+}
 
-	// Evict if cache is full
-	if len(bp.pages) >= bp.capacity {
-		if err := bp.evictPage(); err != nil {
-			return nil, err
-		}
+// WritePage updates the cache and marks the page dirty; the write reaches
+// disk once the frame is flushed (see BufferPool's doc comment).
+func (bp *BufferPool) WritePage(pageID int, data []byte) error {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if f, ok := bp.frames[pageID]; ok {
+		f.data = data
+		f.dirty = true
+		f.refBit = true
+		return nil
+	}
+	_, err := bp.frameFor(pageID, data, true)
+	return err
+}
+
+// PinPage returns the frame for pageID, loading it from disk if
+// necessary, with its pin count incremented so CLOCK skips it until a
+// matching UnpinPage. Callers that need to read, modify, and write a page
+// as one step without an eviction stealing it in between should pin it
+// first.
+func (bp *BufferPool) PinPage(pageID int) (*Frame, error) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if f, ok := bp.frames[pageID]; ok {
+		bp.hits++
+		f.refBit = true
+		f.pinCount++
+		return f, nil
 	}
+	bp.misses++
 
-	// Add to cache
-	bp.pages[pageID] = data
-	elem := bp.lru.PushFront(pageID)
-	bp.lruKeys[pageID] = elem
-	return data, nil
+	data, err := bp.storage.ReadPage(pageID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := bp.frameFor(pageID, data, false)
+	if err != nil {
+		return nil, err
+	}
+	f.pinCount++
+	return f, nil
 }
 
-// WritePage writes a page to disk and updates the cache
-func (bp *BufferPool) WritePage(pageID int, data []byte) error {
+// UnpinPage releases one pin PinPage placed on pageID, marking the frame
+// dirty if the caller modified it. It is an error to unpin a page that
+// isn't pinned or isn't cached -- both indicate a mismatched PinPage
+// call.
+func (bp *BufferPool) UnpinPage(pageID int, dirty bool) error {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
 
-	// Write directly to disk (write-through)
-	if err := bp.storage.WritePage(pageID, data); err != nil {
-		return err
+	f, ok := bp.frames[pageID]
+	if !ok {
+		return fmt.Errorf("unpin page %d: not cached", pageID)
 	}
+	if f.pinCount <= 0 {
+		return fmt.Errorf("unpin page %d: not pinned", pageID)
+	}
+	f.pinCount--
+	if dirty {
+		f.dirty = true
+	}
+	return nil
+}
 
-	// Update cache if page exists, or add it
-	if _, ok := bp.pages[pageID]; ok {
-		bp.pages[pageID] = data
-		if elem, exists := bp.lruKeys[pageID]; exists {
-			bp.lru.MoveToFront(elem)
-		}
-	} else {
-		if len(bp.pages) >= bp.capacity {
-			if err := bp.evictPage(); err != nil {
-				return err
-			}
+// FlushAll writes every dirty frame to disk, for checkpointing (see
+// WALManager.Checkpoint) and Close.
+func (bp *BufferPool) FlushAll() error {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	return bp.flushAllLocked()
+}
+
+// frameFor caches data under pageID, evicting first if the pool is full,
+// and returns the new frame. Callers must hold bp.mu.
+func (bp *BufferPool) frameFor(pageID int, data []byte, dirty bool) (*Frame, error) {
+	if len(bp.frames) >= bp.capacity {
+		if err := bp.evictLocked(); err != nil {
+			return nil, err
 		}
-		bp.pages[pageID] = data
-		elem := bp.lru.PushFront(pageID)
-		bp.lruKeys[pageID] = elem
 	}
-	return nil
+	f := &Frame{pageID: pageID, data: data, dirty: dirty, refBit: true}
+	bp.frames[pageID] = f
+	bp.clock = append(bp.clock, f)
+	return f, nil
 }
 
-// evictPage removes the least recently used page from the cache
-func (bp *BufferPool) evictPage() error {
-	if bp.lru.Len() == 0 {
+// evictLocked runs one CLOCK (second-chance) sweep: a pinned frame is
+// always skipped, a frame with refBit set has it cleared and is given a
+// second chance, and the first frame found with refBit already clear is
+// evicted, flushing it first if it's dirty. Callers must hold bp.mu.
+func (bp *BufferPool) evictLocked() error {
+	if len(bp.clock) == 0 {
 		return fmt.Errorf("buffer pool empty")
 	}
 
-	elem := bp.lru.Back()
-	pageID := elem.Value.(int)
-	bp.lru.Remove(elem)
-	delete(bp.pages, pageID)
-	delete(bp.lruKeys, pageID)
-	return nil
+	attempts := 2*len(bp.clock) + 1
+	for i := 0; i < attempts; i++ {
+		idx := bp.hand % len(bp.clock)
+		f := bp.clock[idx]
+
+		if f.pinCount > 0 {
+			bp.hand = (idx + 1) % len(bp.clock)
+			continue
+		}
+		if f.refBit {
+			f.refBit = false
+			bp.hand = (idx + 1) % len(bp.clock)
+			continue
+		}
+
+		if f.dirty {
+			if err := bp.storage.WritePage(f.pageID, f.data); err != nil {
+				return fmt.Errorf("failed to flush dirty page %d on eviction: %v", f.pageID, err)
+			}
+			bp.dirtyFlushes++
+		}
+		bp.clock = append(bp.clock[:idx], bp.clock[idx+1:]...)
+		delete(bp.frames, f.pageID)
+		if len(bp.clock) > 0 {
+			bp.hand = idx % len(bp.clock)
+		} else {
+			bp.hand = 0
+		}
+		bp.evictions++
+		return nil
+	}
+	return fmt.Errorf("buffer pool full: every frame is pinned")
 }
 
-// Close cleans up the buffer pool
+// Close flushes every dirty frame and clears the cache.
 func (bp *BufferPool) Close() error {
-	bp.pages = make(map[int][]byte)
-	bp.lru.Init()
-	bp.lruKeys = make(map[int]*list.Element)
+	bp.mu.Lock()
+	if err := bp.flushAllLocked(); err != nil {
+		bp.mu.Unlock()
+		return err
+	}
+	bp.frames = make(map[int]*Frame)
+	bp.clock = nil
+	bp.hand = 0
+	bp.mu.Unlock()
+	return nil
+}
+
+// flushAllLocked is FlushAll's body, for callers that already hold bp.mu.
+func (bp *BufferPool) flushAllLocked() error {
+	for _, f := range bp.clock {
+		if !f.dirty {
+			continue
+		}
+		if err := bp.storage.WritePage(f.pageID, f.data); err != nil {
+			return fmt.Errorf("failed to flush page %d: %v", f.pageID, err)
+		}
+		f.dirty = false
+		bp.dirtyFlushes++
+	}
 	return nil
 }
@@ -0,0 +1,108 @@
+package graphdb
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+)
+
+// CodecFor resolves a name to a Codec, mirroring EncoderFor's pattern for
+// import/export formats. Recognized names are "compact", "gob", and
+// "proto".
+func CodecFor(name string) (Codec, error) {
+	switch name {
+	case "compact":
+		return CompactCodec{}, nil
+	case "gob":
+		return GobCodec{}, nil
+	case "proto":
+		return ProtoCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported codec: %s", name)
+	}
+}
+
+// Codec converts a Node or Edge to and from the byte slice RecordManager
+// stores inside a page slot. RecordManager does not tag records with
+// which codec wrote them, so a store must be read back with whatever
+// codec it was written with.
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte, v interface{}) error
+}
+
+// CompactCodec is RecordManager's default codec: the hand-rolled,
+// length-prefixed binary layout implemented by Serialize/Deserialize in
+// utils.go. It remains the default so existing data files keep reading
+// correctly.
+type CompactCodec struct{}
+
+// Encode implements Codec.
+func (CompactCodec) Encode(v interface{}) ([]byte, error) {
+	return Serialize(v)
+}
+
+// Decode implements Codec.
+func (CompactCodec) Decode(data []byte, v interface{}) error {
+	return Deserialize(data, v)
+}
+
+func init() {
+	// Property.Value is stored as interface{}; gob needs every concrete
+	// type that can appear behind it registered up front, one per
+	// PropertyType variant.
+	gob.Register(int64(0))
+	gob.Register("")
+	gob.Register(false)
+	gob.Register(float64(0))
+	gob.Register(time.Time{})
+	gob.Register([]byte(nil))
+	gob.Register([]interface{}(nil))
+	gob.Register(map[string]interface{}(nil))
+}
+
+// GobCodec stores records with the standard library's encoding/gob,
+// trading CompactCodec's smaller footprint for gob's tolerance of struct
+// fields added after a record was written.
+type GobCodec struct{}
+
+// Encode implements Codec.
+func (GobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to gob-encode record: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode implements Codec.
+func (GobCodec) Decode(data []byte, v interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return fmt.Errorf("failed to gob-decode record: %v", err)
+	}
+	return nil
+}
+
+// errProtoCodecUnavailable explains why ProtoCodec can't actually encode
+// or decode anything in this build.
+var errProtoCodecUnavailable = fmt.Errorf("ProtoCodec requires a generated protobuf binding for NodeRecord/EdgeRecord, which this build does not vendor -- see proto/graphdb.proto")
+
+// ProtoCodec is the Codec RecordManager will use once this repository
+// vendors a Protocol Buffers runtime and the generated bindings for the
+// record messages described in proto/graphdb.proto. Neither is available
+// in this build -- the same gap that left proto/graphdb.proto a
+// schema-only file backing server.go's HTTP/JSON substitute for gRPC --
+// so ProtoCodec reports that honestly rather than guessing at a wire
+// format nothing here can actually decode.
+type ProtoCodec struct{}
+
+// Encode implements Codec.
+func (ProtoCodec) Encode(v interface{}) ([]byte, error) {
+	return nil, errProtoCodecUnavailable
+}
+
+// Decode implements Codec.
+func (ProtoCodec) Decode(data []byte, v interface{}) error {
+	return errProtoCodecUnavailable
+}
@@ -0,0 +1,852 @@
+package graphdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Tags identifying each field in version=2's TLV record layout (see
+// SerializeTo/DeserializeFrom). Every field is written as
+// {tag byte, length uvarint, payload}, so a reader that doesn't recognize
+// a tag -- TagUnknown, or any tag a future writer adds -- can skip it by
+// its length instead of failing to decode the record at all. The same
+// length-bounded skip applies to a *known* tag's own payload too: a
+// writer could one day append a sub-field this reader doesn't know about
+// within, say, TagLabels, and it would still decode the labels it
+// understands and silently drop the rest.
+const (
+	TagUnknown    uint8 = 0
+	TagID         uint8 = 1
+	TagActive     uint8 = 2
+	TagLabels     uint8 = 3
+	TagType       uint8 = 4
+	TagSource     uint8 = 5
+	TagTarget     uint8 = 6
+	TagCascade    uint8 = 7
+	TagOrdinal    uint8 = 8
+	TagProperties uint8 = 9
+	TagWeight     uint8 = 10
+)
+
+// recordVersion2 is the version byte SerializeTo writes and
+// DeserializeFrom dispatches version=2's TLV layout on. version=1 (see
+// deserializeV1Body in utils.go) is read-only from here on.
+const recordVersion2 byte = 2
+
+// SerializeTo streams a Node or Edge directly to w as a version=2 TLV
+// record, without ever buffering the whole thing -- Serialize wraps this
+// around a bytes.Buffer for callers that want a []byte. Each field's
+// length is computed analytically (see sizeOfValue) so even a large
+// PropertyBytes blob is written straight to w exactly once.
+func SerializeTo(w io.Writer, v interface{}) error {
+	if _, err := w.Write([]byte{recordVersion2}); err != nil {
+		return fmt.Errorf("failed to write version: %v", err)
+	}
+	switch val := v.(type) {
+	case Node:
+		return serializeNodeV2(w, val)
+	case Edge:
+		return serializeEdgeV2(w, val)
+	default:
+		return fmt.Errorf("unsupported type for serialization: %T", v)
+	}
+}
+
+// DeserializeFrom reads a Node or Edge from r, dispatching on its version
+// byte to either version=1's positional layout (read-only) or version=2's
+// TLV layout. Deserialize wraps this around a bytes.Reader for callers
+// that already have the whole record in memory.
+func DeserializeFrom(r io.Reader, v interface{}) error {
+	br := bufio.NewReader(r)
+	version, err := br.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read version: %v", err)
+	}
+	switch version {
+	case 1:
+		// deserializeV1Body's bounds checks are written against
+		// bytes.Reader.Len(), so the legacy path needs the rest of the
+		// record materialized rather than read incrementally -- version=1
+		// was never meant to stream, and isn't written anymore.
+		rest, err := io.ReadAll(br)
+		if err != nil {
+			return fmt.Errorf("failed to read version=1 payload: %v", err)
+		}
+		return deserializeV1Body(bytes.NewReader(rest), v)
+	case recordVersion2:
+		return deserializeV2Body(br, v)
+	default:
+		return fmt.Errorf("unsupported version: %d", version)
+	}
+}
+
+// tlvField writes one TLV field: tag, then size as an uvarint, then
+// whatever writePayload streams to w. size must exactly match the bytes
+// writePayload writes.
+func tlvField(w io.Writer, tag uint8, size int, writePayload func(io.Writer) error) error {
+	if _, err := w.Write([]byte{tag}); err != nil {
+		return fmt.Errorf("failed to write tag %d: %v", tag, err)
+	}
+	if err := putUvarint(w, uint64(size)); err != nil {
+		return fmt.Errorf("failed to write length for tag %d: %v", tag, err)
+	}
+	return writePayload(w)
+}
+
+func putUvarint(w io.Writer, v uint64) error {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	_, err := w.Write(tmp[:n])
+	return err
+}
+
+func putVarint(w io.Writer, v int64) error {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	_, err := w.Write(tmp[:n])
+	return err
+}
+
+func sizeUvarint(v uint64) int {
+	var tmp [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(tmp[:], v)
+}
+
+func sizeVarint(v int64) int {
+	var tmp [binary.MaxVarintLen64]byte
+	return binary.PutVarint(tmp[:], v)
+}
+
+func serializeNodeV2(w io.Writer, val Node) error {
+	if err := tlvField(w, TagID, sizeVarint(val.ID), func(w io.Writer) error {
+		return putVarint(w, val.ID)
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagActive, 1, func(w io.Writer) error {
+		_, err := w.Write([]byte{btoi(val.Active)})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	labelsSize := sizeUvarint(uint64(len(val.Labels)))
+	for _, label := range val.Labels {
+		labelsSize += sizeUvarint(uint64(len(label))) + len(label)
+	}
+	if err := tlvField(w, TagLabels, labelsSize, func(w io.Writer) error {
+		return writeLabelsV2(w, val.Labels)
+	}); err != nil {
+		return err
+	}
+
+	propsSize, err := sizeOfProperties(val.Properties)
+	if err != nil {
+		return err
+	}
+	if err := tlvField(w, TagProperties, propsSize, func(w io.Writer) error {
+		return writePropertiesV2(w, val.Properties)
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagWeight, 8, func(w io.Writer) error {
+		return binary.Write(w, binary.LittleEndian, val.Weight)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func serializeEdgeV2(w io.Writer, val Edge) error {
+	if err := tlvField(w, TagID, sizeVarint(val.ID), func(w io.Writer) error {
+		return putVarint(w, val.ID)
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagActive, 1, func(w io.Writer) error {
+		_, err := w.Write([]byte{btoi(val.Active)})
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagType, sizeUvarint(uint64(len(val.Type)))+len(val.Type), func(w io.Writer) error {
+		if err := putUvarint(w, uint64(len(val.Type))); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, val.Type)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagSource, sizeVarint(val.Source), func(w io.Writer) error {
+		return putVarint(w, val.Source)
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagTarget, sizeVarint(val.Target), func(w io.Writer) error {
+		return putVarint(w, val.Target)
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagCascade, 1, func(w io.Writer) error {
+		_, err := w.Write([]byte{cascadeSpecToByte(val.Cascade)})
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagOrdinal, sizeVarint(int64(val.Ordinal)), func(w io.Writer) error {
+		return putVarint(w, int64(val.Ordinal))
+	}); err != nil {
+		return err
+	}
+
+	propsSize, err := sizeOfProperties(val.Properties)
+	if err != nil {
+		return err
+	}
+	if err := tlvField(w, TagProperties, propsSize, func(w io.Writer) error {
+		return writePropertiesV2(w, val.Properties)
+	}); err != nil {
+		return err
+	}
+	if err := tlvField(w, TagWeight, 8, func(w io.Writer) error {
+		return binary.Write(w, binary.LittleEndian, val.Weight)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func writeLabelsV2(w io.Writer, labels []string) error {
+	if err := putUvarint(w, uint64(len(labels))); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if err := putUvarint(w, uint64(len(label))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sizeOfProperties(props []Property) (int, error) {
+	size := sizeUvarint(uint64(len(props)))
+	for _, p := range props {
+		sz, err := sizeOfProperty(p)
+		if err != nil {
+			return 0, fmt.Errorf("failed to size property %q: %v", p.Key, err)
+		}
+		size += sz
+	}
+	return size, nil
+}
+
+func writePropertiesV2(w io.Writer, props []Property) error {
+	if err := putUvarint(w, uint64(len(props))); err != nil {
+		return err
+	}
+	for _, p := range props {
+		if err := writePropertyV2(w, p); err != nil {
+			return fmt.Errorf("failed to serialize property %q: %v", p.Key, err)
+		}
+	}
+	return nil
+}
+
+func sizeOfProperty(p Property) (int, error) {
+	valSize, err := sizeOfValue(p.Type, p.Value)
+	if err != nil {
+		return 0, err
+	}
+	return sizeUvarint(uint64(len(p.Key))) + len(p.Key) + 1 + valSize, nil
+}
+
+func writePropertyV2(w io.Writer, p Property) error {
+	if err := putUvarint(w, uint64(len(p.Key))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, p.Key); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{byte(p.Type)}); err != nil {
+		return err
+	}
+	return writeValueV2(w, p.Type, p.Value)
+}
+
+// sizeOfValue mirrors writeValueV2 exactly, computing the byte length its
+// encoding would take without writing anything -- needed so the TLV field
+// wrapping a property (or a whole Properties list) can announce its
+// length up front while still streaming the real write directly to w.
+func sizeOfValue(propType PropertyType, value interface{}) (int, error) {
+	switch propType {
+	case PropertyInt:
+		v, ok := value.(int64)
+		if !ok {
+			return 0, fmt.Errorf("invalid int64 value: %T", value)
+		}
+		return sizeVarint(v), nil
+	case PropertyString:
+		v, ok := value.(string)
+		if !ok {
+			return 0, fmt.Errorf("invalid string value: %T", value)
+		}
+		return sizeUvarint(uint64(len(v))) + len(v), nil
+	case PropertyBool:
+		return 1, nil
+	case PropertyFloat:
+		return 8, nil
+	case PropertyTimestamp:
+		return 8, nil
+	case PropertyBytes:
+		v, ok := value.([]byte)
+		if !ok {
+			return 0, fmt.Errorf("invalid []byte value: %T", value)
+		}
+		return sizeUvarint(uint64(len(v))) + len(v), nil
+	case PropertyList:
+		v, ok := value.([]interface{})
+		if !ok {
+			return 0, fmt.Errorf("invalid list value: %T", value)
+		}
+		size := sizeUvarint(uint64(len(v)))
+		for i, elem := range v {
+			sz, err := sizeOfTaggedValue(elem)
+			if err != nil {
+				return 0, fmt.Errorf("element %d: %v", i, err)
+			}
+			size += sz
+		}
+		return size, nil
+	case PropertyMap:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("invalid map value: %T", value)
+		}
+		size := sizeUvarint(uint64(len(v)))
+		for mapKey, mapVal := range v {
+			sz, err := sizeOfTaggedValue(mapVal)
+			if err != nil {
+				return 0, fmt.Errorf("key %q: %v", mapKey, err)
+			}
+			size += sizeUvarint(uint64(len(mapKey))) + len(mapKey) + sz
+		}
+		return size, nil
+	case PropertyNull:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unsupported property type %d", propType)
+	}
+}
+
+func sizeOfTaggedValue(value interface{}) (int, error) {
+	propType, err := inferPropertyType(value)
+	if err != nil {
+		return 0, err
+	}
+	sz, err := sizeOfValue(propType, value)
+	if err != nil {
+		return 0, err
+	}
+	return 1 + sz, nil
+}
+
+// writeValueV2 writes propType's value, with no key or type tag of its
+// own (writePropertyV2 writes the tag once up front, and
+// writeTaggedValueV2 writes its own for list/map elements). Unlike
+// version=1's writeValue, string/bytes lengths and list/map counts are
+// uvarints, and PropertyInt uses a zigzag varint -- both typically
+// smaller for the small counts and values real records hold.
+func writeValueV2(w io.Writer, propType PropertyType, value interface{}) error {
+	switch propType {
+	case PropertyInt:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("invalid int64 value: %T", value)
+		}
+		return putVarint(w, v)
+	case PropertyString:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid string value: %T", value)
+		}
+		if err := putUvarint(w, uint64(len(v))); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, v)
+		return err
+	case PropertyBool:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("invalid bool value: %T", value)
+		}
+		_, err := w.Write([]byte{btoi(v)})
+		return err
+	case PropertyFloat:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("invalid float64 value: %T", value)
+		}
+		return binary.Write(w, binary.LittleEndian, v)
+	case PropertyTimestamp:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("invalid time.Time value: %T", value)
+		}
+		return binary.Write(w, binary.LittleEndian, v.UnixNano())
+	case PropertyBytes:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("invalid []byte value: %T", value)
+		}
+		if err := putUvarint(w, uint64(len(v))); err != nil {
+			return err
+		}
+		_, err := w.Write(v)
+		return err
+	case PropertyList:
+		v, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid list value: %T", value)
+		}
+		if err := putUvarint(w, uint64(len(v))); err != nil {
+			return err
+		}
+		for i, elem := range v {
+			if err := writeTaggedValueV2(w, elem); err != nil {
+				return fmt.Errorf("failed to write list element %d: %v", i, err)
+			}
+		}
+		return nil
+	case PropertyMap:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid map value: %T", value)
+		}
+		if err := putUvarint(w, uint64(len(v))); err != nil {
+			return err
+		}
+		for mapKey, mapVal := range v {
+			if err := putUvarint(w, uint64(len(mapKey))); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, mapKey); err != nil {
+				return err
+			}
+			if err := writeTaggedValueV2(w, mapVal); err != nil {
+				return fmt.Errorf("failed to write map value for key %q: %v", mapKey, err)
+			}
+		}
+		return nil
+	case PropertyNull:
+		return nil
+	default:
+		return fmt.Errorf("unsupported property type %d", propType)
+	}
+}
+
+// writeTaggedValueV2 writes a PropertyList element or PropertyMap value:
+// its own type byte (inferred via inferPropertyType) followed by
+// writeValueV2's encoding for it.
+func writeTaggedValueV2(w io.Writer, value interface{}) error {
+	propType, err := inferPropertyType(value)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{byte(propType)}); err != nil {
+		return err
+	}
+	return writeValueV2(w, propType, value)
+}
+
+// deserializeV2Body reads a version=2 TLV record from r (positioned just
+// after the version byte) into a Node or Edge. Fields are read in
+// whatever order they appear -- the request that prompted this format
+// explicitly wants older/newer readers and writers to tolerate field
+// additions, so nothing here assumes v1's fixed field order.
+func deserializeV2Body(r *bufio.Reader, v interface{}) error {
+	switch val := v.(type) {
+	case *Node:
+		return readTLVFields(r, func(tag uint8, fr *bufio.Reader) error {
+			switch tag {
+			case TagID:
+				id, err := binary.ReadVarint(fr)
+				if err != nil {
+					return fmt.Errorf("failed to read node ID: %v", err)
+				}
+				val.ID = id
+			case TagActive:
+				b, err := fr.ReadByte()
+				if err != nil {
+					return fmt.Errorf("failed to read node active flag: %v", err)
+				}
+				val.Active = b != 0
+			case TagLabels:
+				labels, err := readLabelsV2(fr)
+				if err != nil {
+					return err
+				}
+				val.Labels = labels
+			case TagProperties:
+				props, err := readPropertiesV2(fr)
+				if err != nil {
+					return err
+				}
+				val.Properties = props
+			case TagWeight:
+				if err := binary.Read(fr, binary.LittleEndian, &val.Weight); err != nil {
+					return fmt.Errorf("failed to read node weight: %v", err)
+				}
+			}
+			return nil
+		})
+	case *Edge:
+		return readTLVFields(r, func(tag uint8, fr *bufio.Reader) error {
+			switch tag {
+			case TagID:
+				id, err := binary.ReadVarint(fr)
+				if err != nil {
+					return fmt.Errorf("failed to read edge ID: %v", err)
+				}
+				val.ID = id
+			case TagActive:
+				b, err := fr.ReadByte()
+				if err != nil {
+					return fmt.Errorf("failed to read edge active flag: %v", err)
+				}
+				val.Active = b != 0
+			case TagType:
+				n, err := binary.ReadUvarint(fr)
+				if err != nil {
+					return fmt.Errorf("failed to read type length: %v", err)
+				}
+				typeBytes := make([]byte, n)
+				if _, err := io.ReadFull(fr, typeBytes); err != nil {
+					return fmt.Errorf("failed to read edge type: %v", err)
+				}
+				val.Type = string(typeBytes)
+			case TagSource:
+				source, err := binary.ReadVarint(fr)
+				if err != nil {
+					return fmt.Errorf("failed to read source ID: %v", err)
+				}
+				val.Source = source
+			case TagTarget:
+				target, err := binary.ReadVarint(fr)
+				if err != nil {
+					return fmt.Errorf("failed to read target ID: %v", err)
+				}
+				val.Target = target
+			case TagCascade:
+				b, err := fr.ReadByte()
+				if err != nil {
+					return fmt.Errorf("failed to read cascade flags: %v", err)
+				}
+				val.Cascade = cascadeSpecFromByte(b)
+			case TagOrdinal:
+				ordinal, err := binary.ReadVarint(fr)
+				if err != nil {
+					return fmt.Errorf("failed to read ordinal: %v", err)
+				}
+				val.Ordinal = int32(ordinal)
+			case TagProperties:
+				props, err := readPropertiesV2(fr)
+				if err != nil {
+					return err
+				}
+				val.Properties = props
+			case TagWeight:
+				if err := binary.Read(fr, binary.LittleEndian, &val.Weight); err != nil {
+					return fmt.Errorf("failed to read edge weight: %v", err)
+				}
+			}
+			return nil
+		})
+	default:
+		return fmt.Errorf("unsupported type for deserialization: %T", v)
+	}
+}
+
+// readTLVFields reads {tag, length, payload} fields from r until EOF,
+// calling handle with each field's payload bounded to exactly its
+// announced length. A tag handle doesn't recognize -- including
+// TagUnknown -- is simply skipped: handle returning nil without reading
+// anything still works, since any unread bytes in the field are drained
+// afterwards.
+func readTLVFields(r *bufio.Reader, handle func(tag uint8, fr *bufio.Reader) error) error {
+	for {
+		tag, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read field tag: %v", err)
+		}
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("failed to read length for tag %d: %v", tag, err)
+		}
+		lr := &io.LimitedReader{R: r, N: int64(length)}
+		fr := bufio.NewReader(lr)
+		if err := handle(tag, fr); err != nil {
+			return fmt.Errorf("tag %d: %v", tag, err)
+		}
+		if _, err := io.Copy(io.Discard, lr); err != nil {
+			return fmt.Errorf("failed to skip trailing bytes for tag %d: %v", tag, err)
+		}
+	}
+}
+
+func readLabelsV2(r *bufio.Reader) ([]string, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read label count: %v", err)
+	}
+	labels := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read label length at index %d: %v", i, err)
+		}
+		labelBytes := make([]byte, n)
+		if _, err := io.ReadFull(r, labelBytes); err != nil {
+			return nil, fmt.Errorf("failed to read label at index %d: %v", i, err)
+		}
+		labels = append(labels, string(labelBytes))
+	}
+	return labels, nil
+}
+
+func readPropertiesV2(r *bufio.Reader) ([]Property, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read property count: %v", err)
+	}
+	props := make([]Property, count)
+	for i := uint64(0); i < count; i++ {
+		if err := readPropertyV2(r, &props[i]); err != nil {
+			return nil, fmt.Errorf("failed to deserialize property at index %d: %v", i, err)
+		}
+	}
+	return props, nil
+}
+
+func readPropertyV2(r *bufio.Reader, prop *Property) error {
+	keyLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("failed to read key length: %v", err)
+	}
+	keyBytes := make([]byte, keyLen)
+	if _, err := io.ReadFull(r, keyBytes); err != nil {
+		return fmt.Errorf("failed to read key: %v", err)
+	}
+	typeByte, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read type: %v", err)
+	}
+	prop.Type = PropertyType(typeByte)
+	value, err := readValueV2(r, prop.Type)
+	if err != nil {
+		return fmt.Errorf("failed to read value for property %q: %v", keyBytes, err)
+	}
+	prop.Key = string(keyBytes)
+	prop.Value = value
+	return nil
+}
+
+func readValueV2(r *bufio.Reader, propType PropertyType) (interface{}, error) {
+	switch propType {
+	case PropertyInt:
+		v, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read int64 value: %v", err)
+		}
+		return v, nil
+	case PropertyString:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read string length: %v", err)
+		}
+		valueBytes := make([]byte, n)
+		if _, err := io.ReadFull(r, valueBytes); err != nil {
+			return nil, fmt.Errorf("failed to read string value: %v", err)
+		}
+		return string(valueBytes), nil
+	case PropertyBool:
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bool value: %v", err)
+		}
+		return b != 0, nil
+	case PropertyFloat:
+		var v float64
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return nil, fmt.Errorf("failed to read float64 value: %v", err)
+		}
+		return v, nil
+	case PropertyTimestamp:
+		var nanos int64
+		if err := binary.Read(r, binary.LittleEndian, &nanos); err != nil {
+			return nil, fmt.Errorf("failed to read timestamp value: %v", err)
+		}
+		return time.Unix(0, nanos).UTC(), nil
+	case PropertyBytes:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bytes length: %v", err)
+		}
+		valueBytes := make([]byte, n)
+		if _, err := io.ReadFull(r, valueBytes); err != nil {
+			return nil, fmt.Errorf("failed to read bytes value: %v", err)
+		}
+		return valueBytes, nil
+	case PropertyList:
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read list count: %v", err)
+		}
+		elems := make([]interface{}, count)
+		for i := uint64(0); i < count; i++ {
+			elem, err := readTaggedValueV2(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read list element %d: %v", i, err)
+			}
+			elems[i] = elem
+		}
+		return elems, nil
+	case PropertyMap:
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read map count: %v", err)
+		}
+		values := make(map[string]interface{}, count)
+		for i := uint64(0); i < count; i++ {
+			keyLen, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read map key length: %v", err)
+			}
+			keyBytes := make([]byte, keyLen)
+			if _, err := io.ReadFull(r, keyBytes); err != nil {
+				return nil, fmt.Errorf("failed to read map key: %v", err)
+			}
+			val, err := readTaggedValueV2(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read map value for key %q: %v", keyBytes, err)
+			}
+			values[string(keyBytes)] = val
+		}
+		return values, nil
+	case PropertyNull:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported property type %d", propType)
+	}
+}
+
+func readTaggedValueV2(r *bufio.Reader) (interface{}, error) {
+	elemType, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read element type: %v", err)
+	}
+	return readValueV2(r, PropertyType(elemType))
+}
+
+// MigrateFile rewrites every version=1 record in sm's pages to version=2,
+// in place. A record's new encoding must still fit within its existing
+// slot -- MigrateFile doesn't reshuffle or grow pages -- which holds for
+// the common case (TLV's tag/uvarint overhead is usually smaller than
+// version=1's fixed-width counts and lengths) but isn't guaranteed for
+// every record; one that doesn't fit is reported as an error rather than
+// silently truncated or left half-migrated.
+//
+// sm has no way to tell a page's records apart as nodes or edges -- that
+// bookkeeping lives in IndexManager, a layer up -- so each record is
+// decoded as whichever of Node or Edge fully consumes its bytes; see
+// tryDecodeV1.
+func MigrateFile(sm *StorageManager) error {
+	for pageID := 1; pageID < sm.PageCount(); pageID++ {
+		data, err := sm.ReadPage(pageID)
+		if err != nil {
+			return fmt.Errorf("failed to read page %d: %v", pageID, err)
+		}
+		sd := decodeSlotDirectory(data)
+
+		changed := false
+		for slotID := range sd.slots {
+			if sd.slots[slotID].length == 0 {
+				continue
+			}
+			raw, err := sd.get(slotID)
+			if err != nil {
+				return fmt.Errorf("failed to read page %d slot %d: %v", pageID, slotID, err)
+			}
+			if len(raw) == 0 || raw[0] != 1 {
+				continue // already version=2, or not a record this migration understands
+			}
+
+			migrated, err := migrateRecordToV2(raw)
+			if err != nil {
+				return fmt.Errorf("failed to migrate page %d slot %d: %v", pageID, slotID, err)
+			}
+			oldLen := sd.slots[slotID].length
+			if len(migrated) > oldLen {
+				return fmt.Errorf("page %d slot %d: version=2 encoding (%d bytes) no longer fits its version=1 slot (%d bytes)", pageID, slotID, len(migrated), oldLen)
+			}
+
+			offset := sd.slots[slotID].offset
+			copy(sd.page[offset:offset+len(migrated)], migrated)
+			for i := offset + len(migrated); i < offset+oldLen; i++ {
+				sd.page[i] = 0
+			}
+			sd.slots[slotID].length = len(migrated)
+			sd.flushSlot(slotID)
+			changed = true
+		}
+
+		if changed {
+			if err := sm.WritePage(pageID, sd.page); err != nil {
+				return fmt.Errorf("failed to write migrated page %d: %v", pageID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// migrateRecordToV2 re-encodes a version=1 record as version=2.
+func migrateRecordToV2(raw []byte) ([]byte, error) {
+	var node Node
+	if tryDecodeV1(raw, &node) {
+		return Serialize(node)
+	}
+	var edge Edge
+	if tryDecodeV1(raw, &edge) {
+		return Serialize(edge)
+	}
+	return nil, fmt.Errorf("record does not fully decode as a version=1 Node or Edge")
+}
+
+// tryDecodeV1 reports whether raw decodes as v (a *Node or *Edge) via the
+// version=1 layout with every byte consumed. Requiring full consumption,
+// not just a nil error, is what makes this a safe way to tell a Node
+// record from an Edge record: version=1 has no type tag of its own, but
+// Node's and Edge's field layouts diverge quickly enough (Edge's Type
+// string where Node has a label count, for instance) that decoding one as
+// the other either errors via the existing bounds checks or leaves
+// leftover bytes.
+func tryDecodeV1(raw []byte, v interface{}) bool {
+	if len(raw) == 0 || raw[0] != 1 {
+		return false
+	}
+	br := bytes.NewReader(raw[1:])
+	if err := deserializeV1Body(br, v); err != nil {
+		return false
+	}
+	return br.Len() == 0
+}
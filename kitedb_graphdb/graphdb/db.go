@@ -1,5 +1,7 @@
 package graphdb
 
+import "fmt"
+
 // GraphDB is the main database interface
 type GraphDB struct {
 	storage    *StorageManager
@@ -10,10 +12,21 @@ type GraphDB struct {
 	txnMgr     *TransactionManager
 	wal        *WALManager
 	executor   *Executor
+	scripts    *ScriptRuntime
 }
 
-// NewGraphDB initializes a new GraphDB instance
+// NewGraphDB initializes a new GraphDB instance with scripting disabled.
+// See NewGraphDBWithScripting to load .script event handlers at startup.
 func NewGraphDB(filename string, pageSize, bufferCapacity int) (*GraphDB, error) {
+	return NewGraphDBWithScripting(filename, pageSize, bufferCapacity, "", "", false)
+}
+
+// NewGraphDBWithScripting initializes a new GraphDB instance and, if
+// enableScripting is true, loads every *.script file in scriptFolder as an
+// event-handler rule (see ScriptRuntime) and runs entryScript once
+// immediately. scriptFolder/entryScript are ignored when enableScripting
+// is false.
+func NewGraphDBWithScripting(filename string, pageSize, bufferCapacity int, scriptFolder, entryScript string, enableScripting bool) (*GraphDB, error) {
 	storage, err := NewStorageManager(filename, pageSize)
 	if err != nil {
 		return nil, err
@@ -22,12 +35,15 @@ func NewGraphDB(filename string, pageSize, bufferCapacity int) (*GraphDB, error)
 	bufferPool := NewBufferPool(storage, bufferCapacity)
 	indexMgr := NewIndexManager()
 	recordMgr := NewRecordManager(bufferPool, pageSize)
-	wal := NewWALManager()
+	wal, err := NewWALManager(filename + ".wal")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %v", err)
+	}
 	graph := NewGraphManager(bufferPool, indexMgr, recordMgr)
 	txnMgr := NewTransactionManager(wal)
 	executor := NewExecutor(graph, txnMgr)
 
-	return &GraphDB{
+	db := &GraphDB{
 		storage:    storage,
 		bufferPool: bufferPool,
 		indexMgr:   indexMgr,
@@ -36,7 +52,27 @@ func NewGraphDB(filename string, pageSize, bufferCapacity int) (*GraphDB, error)
 		graph:      graph,
 		txnMgr:     txnMgr,
 		executor:   executor,
-	}, nil
+	}
+
+	// Undo any transaction the log never saw a COMMIT frame for (see
+	// WALManager.Recover), then rebuild whatever secondary indexes were
+	// logged against the recovered graph (see RebuildIndexes).
+	if err := wal.Recover(graph); err != nil {
+		return nil, fmt.Errorf("failed to recover WAL: %v", err)
+	}
+	if err := db.RebuildIndexes(); err != nil {
+		return nil, fmt.Errorf("failed to rebuild indexes: %v", err)
+	}
+
+	if enableScripting {
+		scripts, err := NewScriptRuntime(db, scriptFolder, entryScript)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scripts: %v", err)
+		}
+		db.scripts = scripts
+	}
+
+	return db, nil
 }
 
 // ExecuteQuery processes a Cypher query
@@ -59,6 +95,12 @@ func (db *GraphDB) ExecuteQuery(query string) ([]map[string]interface{}, error)
 		return nil, err
 	}
 
+	if db.scripts != nil {
+		if err := db.scripts.fireQueryExecuted(); err != nil {
+			return results, err
+		}
+	}
+
 	return results, nil
 }
 
@@ -78,9 +120,163 @@ func (db *GraphDB) Close() error {
 
 // GetNodeLabels returns all node labels (for debugging)
 func (db *GraphDB) GetNodeLabels() []string {
+	return db.Labels()
+}
+
+// Labels returns every distinct node label currently in use, backing
+// `SHOW LABELS` and letting DESCRIBE DATABASE work against arbitrary
+// schemas instead of a hard-coded "Person".
+func (db *GraphDB) Labels() []string {
 	labels := make([]string, 0, len(db.graph.nodeLabelMap))
 	for label := range db.graph.nodeLabelMap {
 		labels = append(labels, label)
 	}
 	return labels
 }
+
+// RebuildAdjacencyIndex rebuilds the forward/reverse edge adjacency
+// indices from scratch. See GraphManager.RebuildAdjacencyIndex.
+func (db *GraphDB) RebuildAdjacencyIndex() error {
+	return db.graph.RebuildAdjacencyIndex()
+}
+
+// DeleteNodeCascade deletes nodeID, its incident edges, and any other
+// node pulled in by their CascadeSpec flags (see GraphManager.DeleteNodeCascade),
+// as one transaction: every deletion is logged to the WAL through
+// db.txnMgr, and a failure partway through the cascade rolls all of it
+// back rather than leaving the graph half-deleted. This is the same path
+// Cypher's DETACH DELETE uses (see Executor.detachDeleteNode); it exists
+// here too for callers driving deletes directly instead of through
+// ExecuteQuery.
+func (db *GraphDB) DeleteNodeCascade(nodeID int64) (deletedNodes []Node, deletedEdges []Edge, err error) {
+	txnID := db.txnMgr.BeginTransaction()
+
+	deletedNodes, deletedEdges, cascadeErr := db.graph.DeleteNodeCascade(nodeID, make(map[int64]bool))
+
+	// Record whatever was deleted, even on a partial cascade, so a rollback
+	// below has every delete to undo.
+	for _, edge := range deletedEdges {
+		if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpDeleteEdge, EdgeID: edge.ID, Edge: edge}); err != nil {
+			return nil, nil, fmt.Errorf("failed to record cascade delete of edge %d: %v", edge.ID, err)
+		}
+	}
+	for _, node := range deletedNodes {
+		if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpDeleteNode, NodeID: node.ID, Node: node}); err != nil {
+			return nil, nil, fmt.Errorf("failed to record cascade delete of node %d: %v", node.ID, err)
+		}
+	}
+
+	if cascadeErr != nil {
+		if rbErr := db.txnMgr.RollbackTransaction(txnID, db.graph); rbErr != nil {
+			return nil, nil, fmt.Errorf("failed to cascade-delete node %d: %v (rollback also failed: %v)", nodeID, cascadeErr, rbErr)
+		}
+		return nil, nil, fmt.Errorf("failed to cascade-delete node %d: %v", nodeID, cascadeErr)
+	}
+
+	if err := db.txnMgr.CommitTransaction(txnID); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit cascade delete of node %d: %v", nodeID, err)
+	}
+	return deletedNodes, deletedEdges, nil
+}
+
+// SetRecordCodec switches which Codec RecordManager uses to encode and
+// decode records from this point on. It does not touch records already
+// on disk, so switching codecs on a non-empty database will break reads
+// of existing data unless the new codec can still parse the old one.
+func (db *GraphDB) SetRecordCodec(codec Codec) {
+	db.recordMgr.SetCodec(codec)
+}
+
+// CreateIndex builds a secondary B+tree index over label's propert(ies),
+// backfills it from every matching node already in the graph, and logs
+// the definition to the WAL so RebuildIndexes can recreate it later. See
+// IndexManager.CreateIndex for what unique and a multi-property props
+// mean.
+func (db *GraphDB) CreateIndex(label string, unique bool, props ...string) error {
+	if err := db.indexMgr.CreateIndex(label, unique, props...); err != nil {
+		return err
+	}
+	def := IndexDef{Label: label, Props: props, Unique: unique}
+	if err := db.indexMgr.populateIndex(def, db.graph); err != nil {
+		_ = db.indexMgr.DropIndex(label, props...)
+		return fmt.Errorf("failed to build index: %v", err)
+	}
+	db.wal.LogIndexDefinition(def)
+	return nil
+}
+
+// DropIndex removes a previously created index. See IndexManager.DropIndex.
+func (db *GraphDB) DropIndex(label string, props ...string) error {
+	return db.indexMgr.DropIndex(label, props...)
+}
+
+// CreateFullTextIndex builds an inverted index over label's propKey
+// string property, backfills it from every matching node already in the
+// graph, and logs the definition to the WAL so RebuildIndexes can
+// recreate it later. Query it in a WHERE clause with
+// FULLTEXT(var.propKey, "some query"); see fulltext.go for the query
+// syntax (whitespace-separated terms, default AND, "OR" to switch, a
+// trailing "*" for a prefix match).
+func (db *GraphDB) CreateFullTextIndex(label, propKey string) error {
+	if err := db.indexMgr.CreateFullTextIndex(label, propKey); err != nil {
+		return err
+	}
+	def := FullTextIndexDef{Label: label, PropKey: propKey}
+	if err := db.indexMgr.populateFullTextIndex(def, db.graph); err != nil {
+		_ = db.indexMgr.DropFullTextIndex(label, propKey)
+		return fmt.Errorf("failed to build full-text index: %v", err)
+	}
+	db.wal.LogFullTextIndexDefinition(def)
+	return nil
+}
+
+// DropFullTextIndex removes a previously created full-text index. See
+// IndexManager.DropFullTextIndex.
+func (db *GraphDB) DropFullTextIndex(label, propKey string) error {
+	return db.indexMgr.DropFullTextIndex(label, propKey)
+}
+
+// RebuildIndexes recreates every property and full-text index
+// definition the WAL has logged and repopulates each from the nodes
+// currently in the graph. NewGraphDBWithScripting calls it at startup,
+// right after WALManager.Recover. The definitions themselves are held
+// only in memory and are not written to the durable log (see
+// WALManager.LogIndexDefinition), so a freshly opened database has none
+// to rebuild.
+func (db *GraphDB) RebuildIndexes() error {
+	for _, def := range db.wal.IndexDefinitions() {
+		if err := db.indexMgr.CreateIndex(def.Label, def.Unique, def.Props...); err != nil {
+			return err
+		}
+		if err := db.indexMgr.populateIndex(def, db.graph); err != nil {
+			return err
+		}
+	}
+	for _, def := range db.wal.FullTextIndexDefinitions() {
+		if err := db.indexMgr.CreateFullTextIndex(def.Label, def.PropKey); err != nil {
+			return err
+		}
+		if err := db.indexMgr.populateFullTextIndex(def, db.graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EdgeTypes returns every distinct relationship type currently in use,
+// backing `SHOW EDGE TYPES`.
+func (db *GraphDB) EdgeTypes() []string {
+	seen := make(map[string]bool)
+	for _, edgeID := range db.indexMgr.GetEdgeIDs() {
+		edge, err := db.graph.GetEdge(edgeID)
+		if err != nil {
+			continue
+		}
+		seen[edge.Type] = true
+	}
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	return types
+}
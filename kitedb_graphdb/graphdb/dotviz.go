@@ -0,0 +1,188 @@
+package graphdb
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Attributes is a Graphviz node/edge attribute set, rendered as
+// `[key="value", ...]`.
+type Attributes map[string]string
+
+// Render writes root as Graphviz DOT to w. root must be an ASTNode (to
+// render a parsed query for debugging, as EXPLAIN does) or a *GraphDB (to
+// render the live graph).
+func Render(w io.Writer, root interface{}) error {
+	switch v := root.(type) {
+	case ASTNode:
+		fmt.Fprintln(w, "digraph AST {")
+		id := 0
+		if _, err := renderASTNode(w, v, &id); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "}")
+		return nil
+	case *GraphDB:
+		return renderGraph(w, v)
+	default:
+		return fmt.Errorf("dotviz: cannot render %T", root)
+	}
+}
+
+// renderASTNode writes node and its children, returning the DOT
+// identifier assigned to node so the caller can draw an edge to it.
+func renderASTNode(w io.Writer, node ASTNode, nextID *int) (string, error) {
+	id := fmt.Sprintf("n%d", *nextID)
+	*nextID++
+	label := fmt.Sprintf("%d", node.Type)
+	if node.Value != "" {
+		label = fmt.Sprintf("%d: %s", node.Type, node.Value)
+	}
+	if err := writeNode(w, id, Attributes{"label": label}); err != nil {
+		return "", err
+	}
+	for _, child := range node.Children {
+		childID, err := renderASTNode(w, child, nextID)
+		if err != nil {
+			return "", err
+		}
+		if err := writeEdge(w, id, childID, nil); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// renderGraph writes every active node and edge in db as a digraph,
+// labeling nodes by their ID and first label and edges by their type.
+func renderGraph(w io.Writer, db *GraphDB) error {
+	fmt.Fprintln(w, "digraph G {")
+
+	nodeIDs := db.indexMgr.GetNodeIDs()
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+	for _, id := range nodeIDs {
+		node, err := db.graph.GetNode(id)
+		if err != nil || !node.Active {
+			continue
+		}
+		label := fmt.Sprintf("%d", node.ID)
+		if len(node.Labels) > 0 {
+			label = fmt.Sprintf("%d:%s", node.ID, node.Labels[0])
+		}
+		if err := writeNode(w, dotNodeName(node.ID), Attributes{"label": label}); err != nil {
+			return err
+		}
+	}
+
+	edgeIDs := db.indexMgr.GetEdgeIDs()
+	sort.Slice(edgeIDs, func(i, j int) bool { return edgeIDs[i] < edgeIDs[j] })
+	for _, id := range edgeIDs {
+		edge, err := db.graph.GetEdge(id)
+		if err != nil || !edge.Active {
+			continue
+		}
+		if err := writeEdge(w, dotNodeName(edge.Source), dotNodeName(edge.Target), Attributes{"label": edge.Type}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+// dotNodeName turns a graph node ID into a DOT-safe identifier.
+func dotNodeName(id int64) string {
+	return fmt.Sprintf("n%d", id)
+}
+
+// writeNode writes a single `id [attrs];` statement.
+func writeNode(w io.Writer, id string, attrs Attributes) error {
+	stmt := id
+	if a := formatAttributes(attrs); a != "" {
+		stmt += " " + a
+	}
+	_, err := fmt.Fprintf(w, "  %s;\n", stmt)
+	return err
+}
+
+// writeEdge writes a single `from -> to [attrs];` statement.
+func writeEdge(w io.Writer, from, to string, attrs Attributes) error {
+	stmt := fmt.Sprintf("%s -> %s", from, to)
+	if a := formatAttributes(attrs); a != "" {
+		stmt += " " + a
+	}
+	_, err := fmt.Fprintf(w, "  %s;\n", stmt)
+	return err
+}
+
+// formatAttributes renders attrs in a deterministic key order so Render's
+// output is stable across runs (useful for diffing EXPLAIN output).
+func formatAttributes(attrs Attributes) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := "["
+	for i, k := range keys {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("%s=%q", k, attrs[k])
+	}
+	return out + "]"
+}
+
+// RenderPNG renders root to DOT (via Render) and pipes it through the
+// system `dot` binary to produce a PNG at path. It returns an error
+// naming the missing binary if Graphviz isn't installed, rather than
+// failing silently.
+func RenderPNG(path string, root interface{}) error {
+	dotPath, err := exec.LookPath("dot")
+	if err != nil {
+		return fmt.Errorf("RenderPNG requires the Graphviz 'dot' binary on PATH: %v", err)
+	}
+
+	cmd := exec.Command(dotPath, "-Tpng", "-o", path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open dot stdin: %v", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer stdin.Close()
+		errCh <- Render(stdin, root)
+	}()
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("dot -Tpng failed: %v: %s", err, out)
+	}
+	if err := <-errCh; err != nil {
+		return fmt.Errorf("failed to render DOT: %v", err)
+	}
+	return nil
+}
+
+// ExplainQuery parses query and returns the Graphviz DOT representation
+// of its AST instead of executing it, backing the EXPLAIN keyword.
+func (db *GraphDB) ExplainQuery(query string) (string, error) {
+	tokenizer := NewTokenizer(query)
+	tokens := tokenizer.Tokenize()
+	parser := NewParser(tokens)
+	ast, err := parser.Parse()
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := Render(&buf, ast); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
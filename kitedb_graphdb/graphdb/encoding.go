@@ -0,0 +1,50 @@
+package graphdb
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Encoder renders query result rows (the []map[string]interface{} produced
+// by Execute) into a byte stream for programmatic consumers, as an
+// alternative to the REPL's hand-rolled tree format.
+type Encoder interface {
+	Encode(results []map[string]interface{}) ([]byte, error)
+}
+
+// EncoderFor resolves a REPL `.format` name to an Encoder.
+func EncoderFor(name string) (Encoder, error) {
+	switch name {
+	case "json":
+		return JSONEncoder{}, nil
+	case "bson":
+		return BSONEncoder{}, nil
+	case "msgpack":
+		return MsgPackEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", name)
+	}
+}
+
+// JSONEncoder renders results as a JSON array.
+type JSONEncoder struct{}
+
+// Encode implements Encoder.
+func (JSONEncoder) Encode(results []map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results as JSON: %v", err)
+	}
+	return data, nil
+}
+
+// ExecuteQueryEncoded runs query and renders its result rows through enc
+// instead of the default []map[string]interface{} shape, for non-Go
+// clients that want BSON/MsgPack/JSON on the wire.
+func (db *GraphDB) ExecuteQueryEncoded(query string, enc Encoder) ([]byte, error) {
+	results, err := db.ExecuteQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Encode(results)
+}
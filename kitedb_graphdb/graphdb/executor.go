@@ -24,6 +24,50 @@ func NewExecutor(graph *GraphManager, txnMgr *TransactionManager) *Executor {
 	}
 }
 
+// parsePropertyValue converts a property/literal value node -- as built by
+// Parser.property, Parser.propertyAssignment, or Parser.listLiteral -- into
+// the Go value and PropertyType pair Property.Value/Property.Type expect.
+// A NodeList value node recurses into its children to build a
+// PropertyList, so a single bracketed literal like `[1, 2, 3]` only needs
+// handling once here rather than in every CREATE/SET call site that builds
+// a Property.
+func parsePropertyValue(valueNode ASTNode) (interface{}, PropertyType, error) {
+	if valueNode.Type == NodeList {
+		elems := make([]interface{}, 0, len(valueNode.Children))
+		for _, child := range valueNode.Children {
+			v, _, err := parsePropertyValue(child)
+			if err != nil {
+				return nil, PropertyNull, err
+			}
+			elems = append(elems, v)
+		}
+		return elems, PropertyList, nil
+	}
+	if len(valueNode.Children) != 1 {
+		return nil, PropertyNull, fmt.Errorf("invalid property value")
+	}
+	switch valueNode.Children[0].Value {
+	case "int":
+		v, err := strconv.ParseInt(valueNode.Value, 10, 64)
+		if err != nil {
+			return nil, PropertyNull, fmt.Errorf("invalid int value: %v", err)
+		}
+		return v, PropertyInt, nil
+	case "float":
+		v, err := strconv.ParseFloat(valueNode.Value, 64)
+		if err != nil {
+			return nil, PropertyNull, fmt.Errorf("invalid float value: %v", err)
+		}
+		return v, PropertyFloat, nil
+	case "string":
+		return valueNode.Value, PropertyString, nil
+	case "bool":
+		return strings.ToLower(valueNode.Value) == "true", PropertyBool, nil
+	default:
+		return nil, PropertyNull, fmt.Errorf("unsupported property type: %s", valueNode.Children[0].Value)
+	}
+}
+
 // Execute processes the AST and returns results
 func (e *Executor) Execute(txnID int64, ast ASTNode) ([]map[string]interface{}, error) {
 	if ast.Type != NodeQuery {
@@ -33,14 +77,23 @@ func (e *Executor) Execute(txnID int64, ast ASTNode) ([]map[string]interface{},
 	e.vars[txnID] = make(map[string]interface{})
 	results := []map[string]interface{}{}
 
-	for _, child := range ast.Children {
+	for i := 0; i < len(ast.Children); i++ {
+		child := ast.Children[i]
 		switch child.Type {
 		case NodeCreate:
 			if err := e.executeCreate(txnID, child); err != nil {
 				return nil, err
 			}
 		case NodeMatch:
-			if err := e.executeMatch(txnID, child); err != nil {
+			// A WHERE immediately following this MATCH is passed along as
+			// a hint so executeMatch can try a secondary property index
+			// instead of a full label scan; executeWhere still runs
+			// afterward regardless; see executeMatch/indexCandidates.
+			var whereHint *ASTNode
+			if i+1 < len(ast.Children) && ast.Children[i+1].Type == NodeWhere {
+				whereHint = &ast.Children[i+1]
+			}
+			if err := e.executeMatch(txnID, child, whereHint); err != nil {
 				return nil, err
 			}
 		case NodeWhere:
@@ -91,28 +144,11 @@ func (e *Executor) executeCreate(txnID int64, node ASTNode) error {
 				}
 				key := child.Children[0].Value
 				valueNode := child.Children[1]
-				if len(valueNode.Children) != 1 {
-					return fmt.Errorf("invalid property value in CREATE")
-				}
-				propType := valueNode.Children[0].Value
-				var value interface{}
-				switch propType {
-				case "int":
-					v, err := strconv.ParseInt(valueNode.Value, 10, 64)
-					if err != nil {
-						return fmt.Errorf("invalid int value: %v", err)
-					}
-					value = v
-					newNode.Properties = append(newNode.Properties, Property{Key: key, Value: value, Type: PropertyInt})
-				case "string":
-					value = valueNode.Value
-					newNode.Properties = append(newNode.Properties, Property{Key: key, Value: value, Type: PropertyString})
-				case "bool":
-					value = strings.ToLower(valueNode.Value) == "true"
-					newNode.Properties = append(newNode.Properties, Property{Key: key, Value: value, Type: PropertyBool})
-				default:
-					return fmt.Errorf("unsupported property type: %s", propType)
+				value, propType, err := parsePropertyValue(valueNode)
+				if err != nil {
+					return fmt.Errorf("invalid property %q in CREATE: %v", key, err)
 				}
+				newNode.Properties = append(newNode.Properties, Property{Key: key, Value: value, Type: propType})
 			}
 		}
 
@@ -167,28 +203,11 @@ func (e *Executor) executeCreate(txnID int64, node ASTNode) error {
 					}
 					key := child.Children[0].Value
 					valueNode := child.Children[1]
-					if len(valueNode.Children) != 1 {
-						return fmt.Errorf("invalid property value in source node")
-					}
-					propType := valueNode.Children[0].Value
-					var value interface{}
-					switch propType {
-					case "int":
-						v, err := strconv.ParseInt(valueNode.Value, 10, 64)
-						if err != nil {
-							return fmt.Errorf("invalid int value: %v", err)
-						}
-						value = v
-						newSource.Properties = append(newSource.Properties, Property{Key: key, Value: value, Type: PropertyInt})
-					case "string":
-						value = valueNode.Value
-						newSource.Properties = append(newSource.Properties, Property{Key: key, Value: value, Type: PropertyString})
-					case "bool":
-						value = strings.ToLower(valueNode.Value) == "true"
-						newSource.Properties = append(newSource.Properties, Property{Key: key, Value: value, Type: PropertyBool})
-					default:
-						return fmt.Errorf("unsupported property type: %s", propType)
+					value, propType, err := parsePropertyValue(valueNode)
+					if err != nil {
+						return fmt.Errorf("invalid property %q in source node: %v", key, err)
 					}
+					newSource.Properties = append(newSource.Properties, Property{Key: key, Value: value, Type: propType})
 				}
 			}
 			var err error
@@ -228,28 +247,11 @@ func (e *Executor) executeCreate(txnID int64, node ASTNode) error {
 					}
 					key := child.Children[0].Value
 					valueNode := child.Children[1]
-					if len(valueNode.Children) != 1 {
-						return fmt.Errorf("invalid property value in target node")
-					}
-					propType := valueNode.Children[0].Value
-					var value interface{}
-					switch propType {
-					case "int":
-						v, err := strconv.ParseInt(valueNode.Value, 10, 64)
-						if err != nil {
-							return fmt.Errorf("invalid int value: %v", err)
-						}
-						value = v
-						newTarget.Properties = append(newTarget.Properties, Property{Key: key, Value: value, Type: PropertyInt})
-					case "string":
-						value = valueNode.Value
-						newTarget.Properties = append(newTarget.Properties, Property{Key: key, Value: value, Type: PropertyString})
-					case "bool":
-						value = strings.ToLower(valueNode.Value) == "true"
-						newTarget.Properties = append(newTarget.Properties, Property{Key: key, Value: value, Type: PropertyBool})
-					default:
-						return fmt.Errorf("unsupported property type: %s", propType)
+					value, propType, err := parsePropertyValue(valueNode)
+					if err != nil {
+						return fmt.Errorf("invalid property %q in target node: %v", key, err)
 					}
+					newTarget.Properties = append(newTarget.Properties, Property{Key: key, Value: value, Type: propType})
 				}
 			}
 			var err error
@@ -278,39 +280,32 @@ func (e *Executor) executeCreate(txnID int64, node ASTNode) error {
 			Target:     targetID,
 			Properties: []Property{},
 			Active:     true,
+			Ordinal:    UnspecifiedOrdinal,
 		}
 		relVar := relNode.Value
 		for _, child := range relNode.Children {
 			if child.Type == NodeType {
 				newEdge.Type = child.Value
+			} else if child.Type == NodeOrdinal {
+				ordinal, err := strconv.ParseInt(child.Value, 10, 32)
+				if err != nil {
+					return fmt.Errorf("invalid ordinal: %v", err)
+				}
+				newEdge.Ordinal = int32(ordinal)
 			} else if child.Type == NodeProperty {
 				if len(child.Children) != 2 {
 					return fmt.Errorf("invalid property in relationship")
 				}
 				key := child.Children[0].Value
 				valueNode := child.Children[1]
-				if len(valueNode.Children) != 1 {
-					return fmt.Errorf("invalid property value in relationship")
+				value, propType, err := parsePropertyValue(valueNode)
+				if err != nil {
+					return fmt.Errorf("invalid property %q in relationship: %v", key, err)
 				}
-				propType := valueNode.Children[0].Value
-				var value interface{}
-				switch propType {
-				case "int":
-					v, err := strconv.ParseInt(valueNode.Value, 10, 64)
-					if err != nil {
-						return fmt.Errorf("invalid int value: %v", err)
-					}
-					value = v
-					newEdge.Properties = append(newEdge.Properties, Property{Key: key, Value: value, Type: PropertyInt})
-				case "string":
-					value = valueNode.Value
-					newEdge.Properties = append(newEdge.Properties, Property{Key: key, Value: value, Type: PropertyString})
-				case "bool":
-					value = strings.ToLower(valueNode.Value) == "true"
-					newEdge.Properties = append(newEdge.Properties, Property{Key: key, Value: value, Type: PropertyBool})
-				default:
-					return fmt.Errorf("unsupported property type: %s", propType)
+				if propType == PropertyBool && applyCascadeProperty(&newEdge.Cascade, key, value.(bool)) {
+					continue
 				}
+				newEdge.Properties = append(newEdge.Properties, Property{Key: key, Value: value, Type: propType})
 			}
 		}
 		if newEdge.Type == "" {
@@ -346,8 +341,32 @@ func (e *Executor) executeCreate(txnID int64, node ASTNode) error {
 	return nil
 }
 
-// executeMatch handles MATCH clauses
-func (e *Executor) executeMatch(txnID int64, node ASTNode) error {
+// applyCascadeProperty sets the CascadeSpec field named by a relationship
+// property key of the form "_cascade_to"/"_cascade_last_to"/
+// "_cascade_from"/"_cascade_last_from" (see CascadeSpec) and reports
+// whether key named a cascade flag at all, so the caller can skip adding
+// it as a regular Property.
+func applyCascadeProperty(cascade *CascadeSpec, key string, value bool) bool {
+	switch key {
+	case "_cascade_to":
+		cascade.CascadeToTarget = value
+	case "_cascade_last_to":
+		cascade.CascadeLastToTarget = value
+	case "_cascade_from":
+		cascade.CascadeFromTarget = value
+	case "_cascade_last_from":
+		cascade.CascadeLastFromTarget = value
+	default:
+		return false
+	}
+	return true
+}
+
+// executeMatch handles MATCH clauses. whereHint, if non-nil, is the WHERE
+// clause immediately following node in the query, consulted only to try a
+// secondary property index for a single-node pattern's candidate list; see
+// indexCandidates.
+func (e *Executor) executeMatch(txnID int64, node ASTNode, whereHint *ASTNode) error {
 	if len(node.Children) != 1 || node.Children[0].Type != NodePattern {
 		return fmt.Errorf("invalid MATCH pattern")
 	}
@@ -365,14 +384,15 @@ func (e *Executor) executeMatch(txnID int64, node ASTNode) error {
 			}
 		}
 
+		var nodeIDs []int64
 		if label == "" {
-			return fmt.Errorf("MATCH requires a label")
-		}
-
-		nodeIDs, exists := e.graph.nodeLabelMap[label]
-		if !exists || len(nodeIDs) == 0 {
-			e.vars[txnID][varName] = []Node{}
-			return nil
+			// MATCH (n) with no label scans every node, regardless of
+			// schema, instead of requiring a hard-coded label.
+			nodeIDs = e.graph.indexManager.GetNodeIDs()
+		} else if ids, ok := e.indexCandidates(label, whereHint, varName); ok {
+			nodeIDs = ids
+		} else {
+			nodeIDs = e.graph.nodeLabelMap[label]
 		}
 
 		nodes := []Node{}
@@ -394,28 +414,49 @@ func (e *Executor) executeMatch(txnID int64, node ASTNode) error {
 		sourceNode := pattern.Children[0]
 		relNode := pattern.Children[1]
 		targetNode := pattern.Children[2]
-		var relType string
+		var relType, direction string
+		var varLen *ASTNode
+		var hasOrdinal bool
+		var wantOrdinal int32
 		relVar := relNode.Value
-		for _, child := range relNode.Children {
-			if child.Type == NodeType {
+		for i, child := range relNode.Children {
+			switch child.Type {
+			case NodeType:
 				relType = child.Value
-				break
+			case NodeDirection:
+				direction = child.Value
+			case NodeVarLength:
+				varLen = &relNode.Children[i]
+			case NodeOrdinal:
+				ordinal, err := strconv.ParseInt(child.Value, 10, 32)
+				if err != nil {
+					return fmt.Errorf("invalid ordinal: %v", err)
+				}
+				hasOrdinal = true
+				wantOrdinal = int32(ordinal)
 			}
 		}
 		if relType == "" {
 			return fmt.Errorf("MATCH requires a relationship type")
 		}
+		if direction == "" {
+			direction = DirectionForward
+		}
 
-		// Get all edge IDs from IndexManager
-		edges := []Edge{}
-		for edgeID := range e.graph.indexManager.edgeIndex {
-			edge, err := e.graph.GetEdge(edgeID)
-			if err != nil {
-				continue
-			}
-			if edge.Active && edge.Type == relType {
-				edges = append(edges, edge)
+		if varLen != nil {
+			minHops, maxHops := parseVarLengthBounds(*varLen)
+			return e.executeVarLengthMatch(txnID, pattern.Value, relVar, sourceNode, targetNode, relType, direction, minHops, maxHops)
+		}
+
+		edges := e.matchEdgesForPattern(txnID, sourceNode, targetNode, relType, direction)
+		if hasOrdinal {
+			filtered := make([]Edge, 0, len(edges))
+			for _, edge := range edges {
+				if edge.Ordinal == wantOrdinal {
+					filtered = append(filtered, edge)
+				}
 			}
+			edges = filtered
 		}
 
 		if relVar != "" {
@@ -426,7 +467,8 @@ func (e *Executor) executeMatch(txnID int64, node ASTNode) error {
 		if sourceNode.Value != "" {
 			nodes := []Node{}
 			for _, edge := range edges {
-				node, err := e.graph.GetNode(edge.Source)
+				left, _ := edgeEndpoints(edge, direction)
+				node, err := e.graph.GetNode(left)
 				if err != nil {
 					continue
 				}
@@ -439,7 +481,8 @@ func (e *Executor) executeMatch(txnID int64, node ASTNode) error {
 		if targetNode.Value != "" {
 			nodes := []Node{}
 			for _, edge := range edges {
-				node, err := e.graph.GetNode(edge.Target)
+				_, right := edgeEndpoints(edge, direction)
+				node, err := e.graph.GetNode(right)
 				if err != nil {
 					continue
 				}
@@ -455,39 +498,402 @@ func (e *Executor) executeMatch(txnID int64, node ASTNode) error {
 	return nil
 }
 
-// executeWhere handles WHERE clauses
-func (e *Executor) executeWhere(txnID int64, node ASTNode) error {
-	log := logrus.WithField("txn_id", txnID)
-	if len(node.Children) != 1 || node.Children[0].Type != NodeExpression {
-		return fmt.Errorf("invalid WHERE expression")
+// indexCandidates tries to use a secondary property index (see
+// IndexManager.CreateIndex) or full-text index (see
+// IndexManager.CreateFullTextIndex) to produce the candidate node IDs
+// for a single-node MATCH pattern on label, given the WHERE clause
+// immediately following it (if any). It only recognizes the simplest
+// shape -- one comparison directly under WHERE on varName's own
+// property, not one combined via AND/OR/NOT -- since that's what a
+// single Seek/Prefix/Search call can serve; anything else reports
+// ok=false so the caller falls back to its normal label scan.
+// executeWhere still re-applies the full predicate afterward
+// regardless, so an index lookup only needs to be a safe (possibly
+// over-inclusive) superset, not exact -- <,<=,> and >= all Seek with
+// one side unbounded rather than computing an exclusive bound.
+func (e *Executor) indexCandidates(label string, whereHint *ASTNode, varName string) ([]int64, bool) {
+	if whereHint == nil || len(whereHint.Children) != 1 {
+		return nil, false
+	}
+	expr := whereHint.Children[0]
+	if expr.Type != NodeBinaryOp || len(expr.Children) != 2 {
+		return nil, false
 	}
-	expr := node.Children[0]
-	if len(expr.Children) != 3 {
-		return fmt.Errorf("invalid expression format")
+	propRef := expr.Children[0]
+	if propRef.Type != NodeIdentifier || propRef.Value != varName || len(propRef.Children) != 1 {
+		return nil, false
 	}
+	key := propRef.Children[0].Value
 
-	varName := expr.Children[0].Value
-	key := expr.Children[1].Value
-	valueNode := expr.Children[2]
-	if len(valueNode.Children) != 1 {
-		return fmt.Errorf("invalid expression value")
+	if expr.Value == OpFullText {
+		ftIdx, exists := e.graph.indexManager.LookupFullTextIndex(label, key)
+		if !exists || len(expr.Children[1].Children) != 1 || expr.Children[1].Children[0].Value != "string" {
+			return nil, false
+		}
+		return ftIdx.Search(expr.Children[1].Value), true
 	}
-	propType := valueNode.Children[0].Value
 
-	var expectedValue interface{}
-	switch propType {
-	case "int":
-		v, err := strconv.ParseInt(valueNode.Value, 10, 64)
+	idx, exists := e.graph.indexManager.LookupIndex(label, key)
+	if !exists {
+		return nil, false
+	}
+
+	switch expr.Value {
+	case OpEqual:
+		v, err := literalValue(expr.Children[1])
 		if err != nil {
-			return fmt.Errorf("invalid int value: %v", err)
+			return nil, false
 		}
-		expectedValue = v
-	case "string":
-		expectedValue = valueNode.Value
-	case "bool":
-		expectedValue = strings.ToLower(valueNode.Value) == "true"
+		return idx.Seek(v, v), true
+	case OpLess, OpLessEqual:
+		v, err := literalValue(expr.Children[1])
+		if err != nil {
+			return nil, false
+		}
+		return idx.Seek(nil, v), true
+	case OpGreater, OpGreaterEqual:
+		v, err := literalValue(expr.Children[1])
+		if err != nil {
+			return nil, false
+		}
+		return idx.Seek(v, nil), true
+	case OpStartsWith:
+		lit := expr.Children[1]
+		if len(lit.Children) != 1 || lit.Children[0].Value != "string" {
+			return nil, false
+		}
+		return idx.Prefix(lit.Value), true
+	default:
+		return nil, false
+	}
+}
+
+// edgeEndpoints maps edge onto the (left, right) node pattern positions
+// implied by direction: forward/either treat the pattern's left node as
+// the source, reverse treats it as the target.
+func edgeEndpoints(edge Edge, direction string) (left, right int64) {
+	if direction == DirectionReverse {
+		return edge.Target, edge.Source
+	}
+	return edge.Source, edge.Target
+}
+
+// matchEdgesForPattern resolves the active edges of relType that satisfy
+// a relationship pattern. When the left or right node of the pattern is
+// already bound to exactly one set of nodes from an earlier clause, it
+// uses the forward/reverse adjacency index to look up candidates in
+// O(deg) instead of scanning every edge in the index.
+func (e *Executor) matchEdgesForPattern(txnID int64, sourceNode, targetNode ASTNode, relType, direction string) []Edge {
+	var candidateIDs []int64
+	indexed := false
+
+	if nodes, ok := e.vars[txnID][sourceNode.Value].([]Node); sourceNode.Value != "" && ok {
+		indexed = true
+		seen := make(map[int64]bool)
+		for _, n := range nodes {
+			for _, id := range adjacencyIDsForLeft(e.graph.indexManager, n.ID, relType, direction) {
+				if !seen[id] {
+					seen[id] = true
+					candidateIDs = append(candidateIDs, id)
+				}
+			}
+		}
+	} else if nodes, ok := e.vars[txnID][targetNode.Value].([]Node); targetNode.Value != "" && ok {
+		indexed = true
+		seen := make(map[int64]bool)
+		for _, n := range nodes {
+			for _, id := range adjacencyIDsForRight(e.graph.indexManager, n.ID, relType, direction) {
+				if !seen[id] {
+					seen[id] = true
+					candidateIDs = append(candidateIDs, id)
+				}
+			}
+		}
+	}
+
+	if !indexed {
+		for edgeID := range e.graph.indexManager.edgeIndex {
+			candidateIDs = append(candidateIDs, edgeID)
+		}
+	}
+
+	edges := []Edge{}
+	for _, edgeID := range candidateIDs {
+		edge, err := e.graph.GetEdge(edgeID)
+		if err != nil {
+			continue
+		}
+		if edge.Active && edge.Type == relType {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// adjacencyIDsForLeft returns the edge IDs touching nodeID on the side
+// that plays the pattern's left-node role for direction.
+func adjacencyIDsForLeft(im *IndexManager, nodeID int64, relType, direction string) []int64 {
+	switch direction {
+	case DirectionReverse:
+		return im.IncomingEdgeIDs(nodeID, relType)
+	case DirectionEither:
+		return append(im.OutgoingEdgeIDs(nodeID, relType), im.IncomingEdgeIDs(nodeID, relType)...)
+	default:
+		return im.OutgoingEdgeIDs(nodeID, relType)
+	}
+}
+
+// adjacencyIDsForRight mirrors adjacencyIDsForLeft for the pattern's
+// right-node role.
+func adjacencyIDsForRight(im *IndexManager, nodeID int64, relType, direction string) []int64 {
+	switch direction {
+	case DirectionReverse:
+		return im.OutgoingEdgeIDs(nodeID, relType)
+	case DirectionEither:
+		return append(im.OutgoingEdgeIDs(nodeID, relType), im.IncomingEdgeIDs(nodeID, relType)...)
 	default:
-		return fmt.Errorf("unsupported property type: %s", propType)
+		return im.IncomingEdgeIDs(nodeID, relType)
+	}
+}
+
+// defaultVarLengthMaxHops bounds a variable-length relationship pattern
+// that omits its upper hop count (e.g. `*2..` or bare `*`).
+// maxVarLengthExpansions caps the total number of edges traverseVarLength
+// will expand across every in-flight path, regardless of hop bounds, so a
+// dense graph can't turn an innocuous-looking query into a runaway scan.
+const (
+	defaultVarLengthMaxHops = 10
+	maxVarLengthExpansions  = 100000
+)
+
+// parseVarLengthBounds reads the (min, max) hop bounds off a NodeVarLength
+// node, applying the parser's omitted-bound conventions: a missing min is
+// 1, a missing max is defaultVarLengthMaxHops.
+func parseVarLengthBounds(node ASTNode) (minHops, maxHops int) {
+	minHops = 1
+	maxHops = defaultVarLengthMaxHops
+	if len(node.Children) != 2 {
+		return
+	}
+	if node.Children[0].Value != "" {
+		if v, err := strconv.Atoi(node.Children[0].Value); err == nil {
+			minHops = v
+		}
+	}
+	if node.Children[1].Value != "" {
+		if v, err := strconv.Atoi(node.Children[1].Value); err == nil {
+			maxHops = v
+		}
+	}
+	return
+}
+
+// resolveNodeIDs finds the candidate node IDs a node pattern refers to:
+// its already-bound variable slice if one exists, otherwise every node
+// with its label (or every node at all, for an unlabeled wildcard).
+func (e *Executor) resolveNodeIDs(txnID int64, nodeNode ASTNode) []int64 {
+	if nodeNode.Value != "" {
+		if nodes, ok := e.vars[txnID][nodeNode.Value].([]Node); ok {
+			ids := make([]int64, 0, len(nodes))
+			for _, n := range nodes {
+				ids = append(ids, n.ID)
+			}
+			return ids
+		}
+	}
+	var label string
+	for _, c := range nodeNode.Children {
+		if c.Type == NodeLabel {
+			label = c.Value
+			break
+		}
+	}
+	if label == "" {
+		return e.graph.indexManager.GetNodeIDs()
+	}
+	return e.graph.nodeLabelMap[label]
+}
+
+// executeVarLengthMatch handles a variable-length relationship pattern
+// like `p = (a)-[:FRIEND*1..3]->(b)`: it enumerates paths via
+// traverseVarLength, keeps only those ending on a node the target pattern
+// allows, binds the endpoints and the relationship variable (if any), and
+// -- if the pattern carries a path variable -- stores the surviving paths
+// under it.
+func (e *Executor) executeVarLengthMatch(txnID int64, pathVar, relVar string, sourceNode, targetNode ASTNode, relType, direction string, minHops, maxHops int) error {
+	sourceIDs := e.resolveNodeIDs(txnID, sourceNode)
+	paths := e.traverseVarLength(sourceIDs, relType, minHops, maxHops, direction)
+
+	allowedTargets := make(map[int64]bool)
+	for _, id := range e.resolveNodeIDs(txnID, targetNode) {
+		allowedTargets[id] = true
+	}
+
+	filtered := make([]Path, 0, len(paths))
+	for _, path := range paths {
+		if allowedTargets[path.NodeIDs[len(path.NodeIDs)-1]] {
+			filtered = append(filtered, path)
+		}
+	}
+
+	if pathVar != "" {
+		e.vars[txnID][pathVar] = filtered
+	}
+	if relVar != "" {
+		e.vars[txnID][relVar] = e.distinctPathEdges(filtered)
+	}
+	if sourceNode.Value != "" {
+		e.vars[txnID][sourceNode.Value] = e.distinctEndpointNodes(filtered, func(p Path) int64 { return p.NodeIDs[0] })
+	}
+	if targetNode.Value != "" {
+		e.vars[txnID][targetNode.Value] = e.distinctEndpointNodes(filtered, func(p Path) int64 { return p.NodeIDs[len(p.NodeIDs)-1] })
+	}
+	return nil
+}
+
+// distinctPathEdges flattens the distinct, active edges spanned by paths,
+// preserving first-seen order, so a variable-length pattern's relationship
+// variable (e.g. `r` in `-[r:FRIEND*1..3]->`) binds to the same kind of
+// edge list a fixed-length match would produce.
+func (e *Executor) distinctPathEdges(paths []Path) []Edge {
+	edges := []Edge{}
+	seen := make(map[int64]bool)
+	for _, path := range paths {
+		for _, edgeID := range path.EdgeIDs {
+			if seen[edgeID] {
+				continue
+			}
+			seen[edgeID] = true
+			if edge, err := e.graph.GetEdge(edgeID); err == nil && edge.Active {
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return edges
+}
+
+// distinctEndpointNodes resolves the distinct, active nodes at the
+// endpoint that pick selects out of paths, preserving first-seen order.
+func (e *Executor) distinctEndpointNodes(paths []Path, pick func(Path) int64) []Node {
+	nodes := []Node{}
+	seen := make(map[int64]bool)
+	for _, path := range paths {
+		id := pick(path)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if n, err := e.graph.GetNode(id); err == nil && n.Active {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// traverseVarLength performs bounded BFS from each of sourceIDs over the
+// forward/reverse adjacency index, enumerating every path connected by
+// minHops..maxHops edges of edgeType. It follows Cypher's relationship
+// isomorphism rule: a path may revisit a node but never traverses the
+// same relationship twice. Each path carries both its node and edge ID
+// chain so callers can bind `p = (a)-[...]->(b)`, its relationship
+// variable, and its endpoints. maxVarLengthExpansions caps total edge
+// expansions across the whole BFS so a dense graph can't turn a loose hop
+// bound into a runaway query.
+func (e *Executor) traverseVarLength(sourceIDs []int64, edgeType string, minHops, maxHops int, direction string) []Path {
+	type frontier struct {
+		path      Path
+		usedEdges map[int64]bool
+	}
+
+	queue := make([]frontier, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		queue = append(queue, frontier{
+			path:      Path{NodeIDs: []int64{id}},
+			usedEdges: map[int64]bool{},
+		})
+	}
+
+	var results []Path
+	expansions := 0
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		hops := len(cur.path.EdgeIDs)
+		if hops >= minHops {
+			results = append(results, cur.path)
+		}
+		if hops >= maxHops || expansions >= maxVarLengthExpansions {
+			continue
+		}
+
+		last := cur.path.NodeIDs[len(cur.path.NodeIDs)-1]
+		for _, edgeID := range adjacencyIDsForLeft(e.graph.indexManager, last, edgeType, direction) {
+			expansions++
+			if cur.usedEdges[edgeID] {
+				continue
+			}
+			edge, err := e.graph.GetEdge(edgeID)
+			if err != nil || !edge.Active {
+				continue
+			}
+			next, ok := neighborVia(edge, last, direction)
+			if !ok {
+				continue
+			}
+			nextUsed := make(map[int64]bool, len(cur.usedEdges)+1)
+			for id := range cur.usedEdges {
+				nextUsed[id] = true
+			}
+			nextUsed[edgeID] = true
+			queue = append(queue, frontier{
+				path: Path{
+					NodeIDs: append(append([]int64{}, cur.path.NodeIDs...), next),
+					EdgeIDs: append(append([]int64{}, cur.path.EdgeIDs...), edgeID),
+				},
+				usedEdges: nextUsed,
+			})
+		}
+	}
+	return results
+}
+
+// neighborVia returns the node reached by walking edge away from from
+// under direction, or false if edge doesn't actually have from on the
+// expected side.
+func neighborVia(edge Edge, from int64, direction string) (int64, bool) {
+	switch direction {
+	case DirectionReverse:
+		if edge.Target == from {
+			return edge.Source, true
+		}
+	case DirectionEither:
+		if edge.Source == from {
+			return edge.Target, true
+		}
+		if edge.Target == from {
+			return edge.Source, true
+		}
+	default:
+		if edge.Source == from {
+			return edge.Target, true
+		}
+	}
+	return 0, false
+}
+
+// executeWhere handles WHERE clauses. The clause's AST (comparisons
+// combined with AND/OR/NOT) is compiled once into a Predicate and then
+// applied to filter the bound variable's node or edge slice.
+func (e *Executor) executeWhere(txnID int64, node ASTNode) error {
+	log := logrus.WithField("txn_id", txnID)
+	if len(node.Children) != 1 {
+		return fmt.Errorf("invalid WHERE expression")
+	}
+
+	varName, pred, err := buildPredicate(node.Children[0])
+	if err != nil {
+		return err
 	}
 
 	obj, exists := e.vars[txnID][varName]
@@ -497,23 +903,17 @@ func (e *Executor) executeWhere(txnID int64, node ASTNode) error {
 
 	if nodes, ok := obj.([]Node); ok {
 		filteredNodes := []Node{}
-		for _, node := range nodes {
-			for _, prop := range node.Properties {
-				if prop.Key == key && prop.Value == expectedValue {
-					filteredNodes = append(filteredNodes, node)
-					break
-				}
+		for _, n := range nodes {
+			if pred.MatchNode(n) {
+				filteredNodes = append(filteredNodes, n)
 			}
 		}
 		e.vars[txnID][varName] = filteredNodes
 	} else if edges, ok := obj.([]Edge); ok {
 		filteredEdges := []Edge{}
 		for _, edge := range edges {
-			for _, prop := range edge.Properties {
-				if prop.Key == key && prop.Value == expectedValue {
-					filteredEdges = append(filteredEdges, edge)
-					break
-				}
+			if pred.MatchEdge(edge) {
+				filteredEdges = append(filteredEdges, edge)
 			}
 		}
 		e.vars[txnID][varName] = filteredEdges
@@ -523,8 +923,6 @@ func (e *Executor) executeWhere(txnID int64, node ASTNode) error {
 
 	log.WithFields(logrus.Fields{
 		"var_name": varName,
-		"key":      key,
-		"value":    expectedValue,
 	}).Info("WHERE filter applied")
 	return nil
 }
@@ -538,34 +936,35 @@ func (e *Executor) executeSet(txnID int64, node ASTNode) error {
 		varName := child.Children[0].Value
 		key := child.Children[1].Value
 		valueNode := child.Children[2]
-		if len(valueNode.Children) != 1 {
-			return fmt.Errorf("invalid SET value")
-		}
-		propType := valueNode.Children[0].Value
 
 		obj, exists := e.vars[txnID][varName]
 		if !exists {
 			return fmt.Errorf("variable %s not found", varName)
 		}
 
-		var prop Property
-		switch propType {
-		case "int":
-			v, err := strconv.ParseInt(valueNode.Value, 10, 64)
+		if valueNode.Type == NodeBinaryOp && valueNode.Value == OpAdd {
+			if key != "weight" {
+				return fmt.Errorf("SET %s.%s = %s.%s + ... is only supported for the weight property", varName, key, varName, key)
+			}
+			delta, err := strconv.ParseFloat(valueNode.Children[0].Value, 64)
 			if err != nil {
-				return fmt.Errorf("invalid int value: %v", err)
+				return fmt.Errorf("invalid SET increment: %v", err)
 			}
-			prop = Property{Key: key, Value: v, Type: PropertyInt}
-		case "string":
-			prop = Property{Key: key, Value: valueNode.Value, Type: PropertyString}
-		case "bool":
-			prop = Property{Key: key, Value: strings.ToLower(valueNode.Value) == "true", Type: PropertyBool}
-		default:
-			return fmt.Errorf("unsupported property type: %s", propType)
+			if err := e.incrementWeight(txnID, varName, obj, delta); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, propType, err := parsePropertyValue(valueNode)
+		if err != nil {
+			return fmt.Errorf("invalid SET value: %v", err)
 		}
+		prop := Property{Key: key, Value: value, Type: propType}
 
 		if nodes, ok := obj.([]Node); ok {
 			for _, node := range nodes {
+				before := node.Properties
 				if err := e.graph.UpdateNode(node.ID, []Property{prop}); err != nil {
 					return fmt.Errorf("failed to update node %d: %v", node.ID, err)
 				}
@@ -574,12 +973,14 @@ func (e *Executor) executeSet(txnID int64, node ASTNode) error {
 					Type:       OpUpdateNode,
 					NodeID:     node.ID,
 					Properties: []Property{prop},
+					Before:     before,
 				}); err != nil {
 					return fmt.Errorf("failed to record operation: %v", err)
 				}
 			}
 		} else if edges, ok := obj.([]Edge); ok {
 			for _, edge := range edges {
+				before := edge.Properties
 				if err := e.graph.UpdateEdge(edge.ID, []Property{prop}); err != nil {
 					return fmt.Errorf("failed to update edge %d: %v", edge.ID, err)
 				}
@@ -588,6 +989,7 @@ func (e *Executor) executeSet(txnID int64, node ASTNode) error {
 					Type:       OpUpdateEdge,
 					EdgeID:     edge.ID,
 					Properties: []Property{prop},
+					Before:     before,
 				}); err != nil {
 					return fmt.Errorf("failed to record operation: %v", err)
 				}
@@ -599,8 +1001,55 @@ func (e *Executor) executeSet(txnID int64, node ASTNode) error {
 	return nil
 }
 
-// executeDelete handles DELETE clauses
+// incrementWeight applies a `SET var.weight = var.weight + delta` clause
+// to every node or edge bound to varName, via GraphManager.IncrementNodeWeight/
+// IncrementEdgeWeight rather than the ordinary property-merge path, and
+// records each as a WeightChanged TransactionOperation so Rollback can
+// restore the prior weight (see executeSet's NodeBinaryOp branch).
+func (e *Executor) incrementWeight(txnID int64, varName string, obj interface{}, delta float64) error {
+	if nodes, ok := obj.([]Node); ok {
+		for _, node := range nodes {
+			before := node.Weight
+			if _, err := e.graph.IncrementNodeWeight(node.ID, delta); err != nil {
+				return fmt.Errorf("failed to increment weight of node %d: %v", node.ID, err)
+			}
+			if err := e.txnMgr.RecordOperation(txnID, TransactionOperation{
+				Type:          OpUpdateNode,
+				NodeID:        node.ID,
+				Before:        node.Properties,
+				WeightChanged: true,
+				BeforeWeight:  before,
+			}); err != nil {
+				return fmt.Errorf("failed to record operation: %v", err)
+			}
+		}
+		return nil
+	}
+	if edges, ok := obj.([]Edge); ok {
+		for _, edge := range edges {
+			before := edge.Weight
+			if _, err := e.graph.IncrementEdgeWeight(edge.ID, delta); err != nil {
+				return fmt.Errorf("failed to increment weight of edge %d: %v", edge.ID, err)
+			}
+			if err := e.txnMgr.RecordOperation(txnID, TransactionOperation{
+				Type:          OpUpdateEdge,
+				EdgeID:        edge.ID,
+				Before:        edge.Properties,
+				WeightChanged: true,
+				BeforeWeight:  before,
+			}); err != nil {
+				return fmt.Errorf("failed to record operation: %v", err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("variable %s is not a node or edge list", varName)
+}
+
+// executeDelete handles DELETE and DETACH DELETE clauses
 func (e *Executor) executeDelete(txnID int64, node ASTNode) error {
+	detach := node.Value == "DETACH"
+
 	for _, child := range node.Children {
 		if child.Type != NodeIdentifier {
 			return fmt.Errorf("invalid DELETE identifier")
@@ -613,6 +1062,13 @@ func (e *Executor) executeDelete(txnID int64, node ASTNode) error {
 
 		if nodes, ok := obj.([]Node); ok {
 			for _, node := range nodes {
+				if detach {
+					if err := e.detachDeleteNode(txnID, node.ID); err != nil {
+						return err
+					}
+					continue
+				}
+
 				if err := e.graph.DeleteNode(node.ID); err != nil {
 					return fmt.Errorf("failed to delete node %d: %v", node.ID, err)
 				}
@@ -620,6 +1076,7 @@ func (e *Executor) executeDelete(txnID int64, node ASTNode) error {
 				if err := e.txnMgr.RecordOperation(txnID, TransactionOperation{
 					Type:   OpDeleteNode,
 					NodeID: node.ID,
+					Node:   node,
 				}); err != nil {
 					return fmt.Errorf("failed to record operation: %v", err)
 				}
@@ -634,6 +1091,7 @@ func (e *Executor) executeDelete(txnID int64, node ASTNode) error {
 				if err := e.txnMgr.RecordOperation(txnID, TransactionOperation{
 					Type:   OpDeleteEdge,
 					EdgeID: edge.ID,
+					Edge:   edge,
 				}); err != nil {
 					return fmt.Errorf("failed to record operation: %v", err)
 				}
@@ -648,6 +1106,29 @@ func (e *Executor) executeDelete(txnID int64, node ASTNode) error {
 	return nil
 }
 
+// detachDeleteNode removes nodeID's incident edges and, following their
+// cascade flags, any other nodes pulled in with it, then the node
+// itself -- recording every deletion so ROLLBACK can undo the whole
+// thing. See GraphManager.DeleteNodeCascade.
+func (e *Executor) detachDeleteNode(txnID int64, nodeID int64) error {
+	deletedNodes, deletedEdges, err := e.graph.DeleteNodeCascade(nodeID, make(map[int64]bool))
+	if err != nil {
+		return fmt.Errorf("failed to detach delete node %d: %v", nodeID, err)
+	}
+
+	for _, edge := range deletedEdges {
+		if err := e.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpDeleteEdge, EdgeID: edge.ID, Edge: edge}); err != nil {
+			return fmt.Errorf("failed to record operation: %v", err)
+		}
+	}
+	for _, node := range deletedNodes {
+		if err := e.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpDeleteNode, NodeID: node.ID, Node: node}); err != nil {
+			return fmt.Errorf("failed to record operation: %v", err)
+		}
+	}
+	return nil
+}
+
 // executeReturn handles RETURN clauses
 func (e *Executor) executeReturn(txnID int64, node ASTNode) ([]map[string]interface{}, error) {
 	results := []map[string]interface{}{}
@@ -688,6 +1169,7 @@ func (e *Executor) executeReturn(txnID int64, node ASTNode) ([]map[string]interf
 							"type":       edge.Type,
 							"source":     edge.Source,
 							"target":     edge.Target,
+							"ordinal":    edge.Ordinal,
 							"properties": edge.Properties,
 						},
 					}
@@ -695,8 +1177,23 @@ func (e *Executor) executeReturn(txnID int64, node ASTNode) ([]map[string]interf
 					results = append(results, result)
 				}
 			}
+		} else if paths, ok := obj.([]Path); ok {
+			for _, path := range paths {
+				key := fmt.Sprintf("path:%v:%v", path.NodeIDs, path.EdgeIDs)
+				if _, exists := uniqueItems[key]; !exists {
+					result := map[string]interface{}{
+						varName: map[string]interface{}{
+							"nodes": path.NodeIDs,
+							"edges": path.EdgeIDs,
+							"hops":  len(path.EdgeIDs),
+						},
+					}
+					uniqueItems[key] = result
+					results = append(results, result)
+				}
+			}
 		} else {
-			return nil, fmt.Errorf("variable %s is not a node or edge list", varName)
+			return nil, fmt.Errorf("variable %s is not a node, edge, or path list", varName)
 		}
 	}
 
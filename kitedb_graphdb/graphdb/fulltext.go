@@ -0,0 +1,377 @@
+package graphdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// FullTextIndexDef identifies a full-text index: the node label it
+// covers and the single string property key it tokenizes. It's the unit
+// CreateFullTextIndex/DropFullTextIndex operate on and the one the WAL
+// persists so RebuildIndexes can recreate it, the same way IndexDef
+// works for property_index.go's B+tree indexes.
+type FullTextIndexDef struct {
+	Label   string
+	PropKey string
+}
+
+// key is the dotted "Label.propKey" name CreateFullTextIndex/
+// DropFullTextIndex key IndexManager.fulltext by.
+func (d FullTextIndexDef) key() string {
+	return d.Label + "." + d.PropKey
+}
+
+// fullTextIndex is an inverted index over one label's string property:
+// term -> the sorted, deduplicated node IDs whose tokenized property
+// value contains it. Like BPlusTreeIndex (see bplustree.go), it lives
+// entirely in memory -- RebuildIndexes repopulates it from the
+// WAL-logged definition at startup rather than this being paged through
+// BufferPool/StorageManager.
+type fullTextIndex struct {
+	def      FullTextIndexDef
+	postings map[string][]int64
+}
+
+// newFullTextIndex builds an empty inverted index for def.
+func newFullTextIndex(def FullTextIndexDef) *fullTextIndex {
+	return &fullTextIndex{def: def, postings: make(map[string][]int64)}
+}
+
+// indexText tokenizes text and adds id to every resulting term's
+// posting list.
+func (idx *fullTextIndex) indexText(id int64, text string) {
+	for _, term := range tokenizeText(text) {
+		idx.postings[term] = insertSortedID(idx.postings[term], id)
+	}
+}
+
+// unindexText removes id from every term text tokenizes to, pruning a
+// term's posting list entirely once it's left empty.
+func (idx *fullTextIndex) unindexText(id int64, text string) {
+	for _, term := range tokenizeText(text) {
+		postings := removeSortedID(idx.postings[term], id)
+		if len(postings) == 0 {
+			delete(idx.postings, term)
+		} else {
+			idx.postings[term] = postings
+		}
+	}
+}
+
+// prefixIDs returns the union of every term's posting list where term
+// starts with prefix.
+func (idx *fullTextIndex) prefixIDs(prefix string) []int64 {
+	var union []int64
+	for term, ids := range idx.postings {
+		if strings.HasPrefix(term, prefix) {
+			union = unionSortedIDs(union, ids)
+		}
+	}
+	return union
+}
+
+// Search evaluates query (see parseFullTextQuery) against this index's
+// postings, returning the matching node IDs.
+func (idx *fullTextIndex) Search(query string) []int64 {
+	clauses := parseFullTextQuery(query)
+	var result []int64
+	for i, c := range clauses {
+		var ids []int64
+		if c.prefix {
+			ids = idx.prefixIDs(c.term)
+		} else {
+			ids = idx.postings[c.term]
+		}
+		if i == 0 {
+			result = ids
+			continue
+		}
+		if c.or {
+			result = unionSortedIDs(result, ids)
+		} else {
+			result = intersectSortedIDs(result, ids)
+		}
+	}
+	return result
+}
+
+// fullTextClause is one term of a parsed full-text query: the term
+// itself (lowercased), whether it's prefix-matched (a trailing "*" in
+// the query), and whether it combines with the previous clause via OR
+// rather than the default AND.
+type fullTextClause struct {
+	term   string
+	prefix bool
+	or     bool
+}
+
+// parseFullTextQuery splits a full-text query into clauses on
+// whitespace. Consecutive terms default to AND; a literal "OR" between
+// two terms switches the following term to combine via OR instead (an
+// explicit "AND" is also accepted, though it's already the default); a
+// trailing "*" on a term makes it match by prefix rather than exactly.
+func parseFullTextQuery(query string) []fullTextClause {
+	fields := strings.Fields(query)
+	clauses := make([]fullTextClause, 0, len(fields))
+	or := false
+	for _, f := range fields {
+		if strings.EqualFold(f, "OR") {
+			or = true
+			continue
+		}
+		if strings.EqualFold(f, "AND") {
+			or = false
+			continue
+		}
+		term := strings.ToLower(f)
+		prefix := strings.HasSuffix(term, "*")
+		if prefix {
+			term = strings.TrimSuffix(term, "*")
+		}
+		clauses = append(clauses, fullTextClause{term: term, prefix: prefix, or: or})
+		or = false
+	}
+	return clauses
+}
+
+// matchesFullText reports whether text's tokenized terms satisfy query,
+// the same evaluation Search applies against an index's postings. It's
+// what a FULLTEXT() comparisonPredicate falls back to when no
+// FullTextIndex covers the property being matched (see
+// Executor.indexCandidates), so FULLTEXT() in a WHERE clause is always
+// correct whether or not an index exists to accelerate it.
+func matchesFullText(text, query string) bool {
+	terms := make(map[string]bool)
+	for _, t := range tokenizeText(text) {
+		terms[t] = true
+	}
+	clauses := parseFullTextQuery(query)
+	if len(clauses) == 0 {
+		return false
+	}
+	var matched bool
+	for i, c := range clauses {
+		hit := terms[c.term]
+		if c.prefix {
+			hit = false
+			for t := range terms {
+				if strings.HasPrefix(t, c.term) {
+					hit = true
+					break
+				}
+			}
+		}
+		if i == 0 {
+			matched = hit
+			continue
+		}
+		if c.or {
+			matched = matched || hit
+		} else {
+			matched = matched && hit
+		}
+	}
+	return matched
+}
+
+// tokenizeText lowercases text and splits it into its alphanumeric
+// words, the same shape a fullTextIndex's postings and a full-text
+// query's clauses are compared in.
+func tokenizeText(text string) []string {
+	var terms []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			terms = append(terms, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// insertSortedID inserts id into the sorted, deduplicated slice ids.
+func insertSortedID(ids []int64, id int64) []int64 {
+	i := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
+	if i < len(ids) && ids[i] == id {
+		return ids
+	}
+	ids = append(ids, 0)
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+	return ids
+}
+
+// removeSortedID removes id from the sorted slice ids, if present.
+func removeSortedID(ids []int64, id int64) []int64 {
+	i := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
+	if i < len(ids) && ids[i] == id {
+		return append(ids[:i], ids[i+1:]...)
+	}
+	return ids
+}
+
+// unionSortedIDs merges two sorted, deduplicated ID slices.
+func unionSortedIDs(a, b []int64) []int64 {
+	result := make([]int64, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	result = append(result, b[j:]...)
+	return result
+}
+
+// intersectSortedIDs intersects two sorted, deduplicated ID slices.
+func intersectSortedIDs(a, b []int64) []int64 {
+	var result []int64
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// CreateFullTextIndex registers an empty inverted index over label's
+// propKey property. It neither backfills it from existing nodes nor logs
+// it to the WAL; GraphDB.CreateFullTextIndex does both around this call
+// (see populateFullTextIndex). Only string-valued properties are indexed; a node
+// missing propKey, or holding a non-string value for it, is simply not
+// represented, the same way indexKey treats a property index.
+func (im *IndexManager) CreateFullTextIndex(label, propKey string) error {
+	if propKey == "" {
+		return fmt.Errorf("CreateFullTextIndex requires a property key")
+	}
+	def := FullTextIndexDef{Label: label, PropKey: propKey}
+	if _, exists := im.fulltext[def.key()]; exists {
+		return fmt.Errorf("full-text index already exists on %s.%s", label, propKey)
+	}
+	im.fulltext[def.key()] = newFullTextIndex(def)
+	return nil
+}
+
+// DropFullTextIndex removes the index CreateFullTextIndex built for
+// label.propKey.
+func (im *IndexManager) DropFullTextIndex(label, propKey string) error {
+	key := (FullTextIndexDef{Label: label, PropKey: propKey}).key()
+	if _, exists := im.fulltext[key]; !exists {
+		return fmt.Errorf("no full-text index on %s.%s", label, propKey)
+	}
+	delete(im.fulltext, key)
+	return nil
+}
+
+// LookupFullTextIndex returns the index on label.propKey, if
+// CreateFullTextIndex has built one.
+func (im *IndexManager) LookupFullTextIndex(label, propKey string) (*fullTextIndex, bool) {
+	idx, exists := im.fulltext[(FullTextIndexDef{Label: label, PropKey: propKey}).key()]
+	return idx, exists
+}
+
+// FullTextIndexDefs returns the definition of every full-text index
+// currently registered, for WALManager.LogFullTextIndexDefinition to
+// persist.
+func (im *IndexManager) FullTextIndexDefs() []FullTextIndexDef {
+	defs := make([]FullTextIndexDef, 0, len(im.fulltext))
+	for _, idx := range im.fulltext {
+		defs = append(defs, idx.def)
+	}
+	return defs
+}
+
+// populateFullTextIndex scans every active node with label def.Label
+// already in graph and indexes its def.PropKey property text. See
+// property_index.go's populateIndex, which this mirrors.
+func (im *IndexManager) populateFullTextIndex(def FullTextIndexDef, graph *GraphManager) error {
+	idx, exists := im.fulltext[def.key()]
+	if !exists {
+		return fmt.Errorf("no full-text index on %s.%s", def.Label, def.PropKey)
+	}
+	for _, nodeID := range graph.nodeLabelMap[def.Label] {
+		node, err := graph.GetNode(nodeID)
+		if err != nil || !node.Active {
+			continue
+		}
+		if text, ok := propertyText(node.Properties, def.PropKey); ok {
+			idx.indexText(nodeID, text)
+		}
+	}
+	return nil
+}
+
+// propertyText looks up key in properties, returning its value as a
+// string and ok=true only if it exists and is PropertyString-typed --
+// full-text indexing is defined over text, not arbitrary property
+// values.
+func propertyText(properties []Property, key string) (string, bool) {
+	v, exists := propertyValue(properties, key)
+	if !exists {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// indexNodeFullText indexes nodeID's def.PropKey property text into
+// every full-text index covering one of labels.
+func (im *IndexManager) indexNodeFullText(labels []string, properties []Property, nodeID int64) {
+	labelSet := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		labelSet[l] = true
+	}
+	for _, idx := range im.fulltext {
+		if !labelSet[idx.def.Label] {
+			continue
+		}
+		if text, ok := propertyText(properties, idx.def.PropKey); ok {
+			idx.indexText(nodeID, text)
+		}
+	}
+}
+
+// unindexNodeFullText removes nodeID from every full-text index
+// covering one of labels.
+func (im *IndexManager) unindexNodeFullText(labels []string, properties []Property, nodeID int64) {
+	labelSet := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		labelSet[l] = true
+	}
+	for _, idx := range im.fulltext {
+		if !labelSet[idx.def.Label] {
+			continue
+		}
+		if text, ok := propertyText(properties, idx.def.PropKey); ok {
+			idx.unindexText(nodeID, text)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package graphdb
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -15,6 +18,17 @@ type GraphManager struct {
 	nextNodeID   int64
 	nextEdgeID   int64
 	nodeLabelMap map[string][]int64 // Label -> Node IDs
+
+	// rules holds every Rule registered via RegisterRule, keyed by the
+	// EventType(s) it handles (see rules.go).
+	rules map[EventType][]Rule
+
+	// weightLocksMu/weightLocks back lockForWeight's per-node mutex map,
+	// the same per-key lock pattern Server.lockFor uses (see server.go),
+	// so IncrementNodeWeight/IncrementEdgeWeight/UpsertEdgeWeight can
+	// read-modify-write a node's Weight atomically under concurrent calls.
+	weightLocksMu sync.Mutex
+	weightLocks   map[string]*sync.Mutex
 }
 
 // NewGraphManager initializes a new GraphManager
@@ -26,6 +40,8 @@ func NewGraphManager(bufferPool *BufferPool, indexManager *IndexManager, recordM
 		nextNodeID:   1,
 		nextEdgeID:   1,
 		nodeLabelMap: make(map[string][]int64),
+		rules:        make(map[EventType][]Rule),
+		weightLocks:  make(map[string]*sync.Mutex),
 	}
 }
 
@@ -33,39 +49,80 @@ func NewGraphManager(bufferPool *BufferPool, indexManager *IndexManager, recordM
 func (gm *GraphManager) AddNode(node Node) (int64, error) {
 	node.ID = gm.nextNodeID
 	node.Active = true
+
+	if err := gm.indexManager.checkUnique(node.Labels, node.Properties, node.ID); err != nil {
+		return 0, err
+	}
+	if err := gm.dispatch(nil, EventBeforeNodeCreated, node); err != nil {
+		return 0, err
+	}
 	gm.nextNodeID++
 
-	pageID, err := gm.recordMgr.WriteRecord(node)
+	recordID, err := gm.recordMgr.WriteRecord(node)
 	if err != nil {
 		return 0, fmt.Errorf("failed to write node: %v", err)
 	}
 
-	if err := gm.indexManager.InsertNode(node.ID, pageID); err != nil {
+	if err := gm.indexManager.InsertNode(node.ID, recordID); err != nil {
 		return 0, fmt.Errorf("failed to insert node into index: %v", err)
 	}
+	if err := gm.indexManager.indexNode(node.Labels, node.Properties, node.ID); err != nil {
+		return 0, fmt.Errorf("failed to index node: %v", err)
+	}
+	gm.indexManager.indexNodeFullText(node.Labels, node.Properties, node.ID)
 
 	for _, label := range node.Labels {
 		gm.nodeLabelMap[label] = append(gm.nodeLabelMap[label], node.ID)
 	}
 
+	if err := gm.dispatch(nil, EventNodeCreated, node); err != nil {
+		return 0, err
+	}
 	return node.ID, nil
 }
 
-// AddEdge adds a new edge to the graph
+// AddEdge adds a new edge to the graph. If edge.Ordinal is
+// UnspecifiedOrdinal, the next free ordinal among edges already sharing
+// its Type, Source and Target is auto-assigned; an explicit ordinal that
+// collides with an existing parallel edge is rejected.
 func (gm *GraphManager) AddEdge(edge Edge) (int64, error) {
+	parallel := gm.edgesBetween(edge.Source, edge.Target, edge.Type)
+	if edge.Ordinal == UnspecifiedOrdinal {
+		var next int32
+		for _, existing := range parallel {
+			if existing.Ordinal >= next {
+				next = existing.Ordinal + 1
+			}
+		}
+		edge.Ordinal = next
+	} else {
+		for _, existing := range parallel {
+			if existing.Ordinal == edge.Ordinal {
+				return 0, fmt.Errorf("ordinal %d already used by a %s edge from %d to %d", edge.Ordinal, edge.Type, edge.Source, edge.Target)
+			}
+		}
+	}
+
 	edge.ID = gm.nextEdgeID
 	edge.Active = true
+	if err := gm.dispatch(nil, EventBeforeEdgeCreated, edge); err != nil {
+		return 0, err
+	}
 	gm.nextEdgeID++
 
-	pageID, err := gm.recordMgr.WriteRecord(edge)
+	recordID, err := gm.recordMgr.WriteRecord(edge)
 	if err != nil {
 		return 0, fmt.Errorf("failed to write edge: %v", err)
 	}
 
-	if err := gm.indexManager.InsertEdge(edge.ID, pageID); err != nil {
+	if err := gm.indexManager.InsertEdge(edge.ID, recordID); err != nil {
 		return 0, fmt.Errorf("failed to insert edge into index: %v", err)
 	}
+	gm.indexManager.IndexEdgeAdjacency(edge)
 
+	if err := gm.dispatch(nil, EventEdgeCreated, edge); err != nil {
+		return 0, err
+	}
 	return edge.ID, nil
 }
 
@@ -77,13 +134,13 @@ func (gm *GraphManager) GetNode(nodeID int64) (Node, error) {
 		log.WithField("duration_ms", time.Since(start).Milliseconds()).Debug("GetNode completed")
 	}()
 
-	pageID, err := gm.indexManager.SearchNode(nodeID)
+	recordID, err := gm.indexManager.SearchNode(nodeID)
 	if err != nil {
 		return Node{}, fmt.Errorf("failed to find node %d: %v", nodeID, err)
 	}
 
 	var node Node
-	if err := gm.recordMgr.ReadRecord(pageID, &node); err != nil {
+	if err := gm.recordMgr.ReadRecord(recordID, &node); err != nil {
 		return Node{}, fmt.Errorf("failed to read node %d: %v", nodeID, err)
 	}
 
@@ -105,13 +162,13 @@ func (gm *GraphManager) GetEdge(edgeID int64) (Edge, error) {
 		log.WithField("duration_ms", time.Since(start).Milliseconds()).Debug("GetEdge completed")
 	}()
 
-	pageID, err := gm.indexManager.SearchEdge(edgeID)
+	recordID, err := gm.indexManager.SearchEdge(edgeID)
 	if err != nil {
 		return Edge{}, fmt.Errorf("failed to find edge %d: %v", edgeID, err)
 	}
 
 	var edge Edge
-	if err := gm.recordMgr.ReadRecord(pageID, &edge); err != nil {
+	if err := gm.recordMgr.ReadRecord(recordID, &edge); err != nil {
 		return Edge{}, fmt.Errorf("failed to read edge %d: %v", edgeID, err)
 	}
 
@@ -122,12 +179,37 @@ func (gm *GraphManager) GetEdge(edgeID int64) (Edge, error) {
 	return edge, nil
 }
 
+// edgesBetween returns the active edges of kind from src to tgt,
+// unsorted. It backs both AddEdge's ordinal bookkeeping and the public
+// GetEdgesBetween.
+func (gm *GraphManager) edgesBetween(src, tgt int64, kind string) []Edge {
+	var matches []Edge
+	for _, edgeID := range gm.indexManager.OutgoingEdgeIDs(src, kind) {
+		edge, err := gm.GetEdge(edgeID)
+		if err != nil || edge.Target != tgt {
+			continue
+		}
+		matches = append(matches, edge)
+	}
+	return matches
+}
+
+// GetEdgesBetween returns every active edge of kind from src to tgt,
+// ordered by Ordinal -- useful for representing ordered arguments or
+// parameters in code-graph style data, where edge order carries meaning.
+func (gm *GraphManager) GetEdgesBetween(src, tgt int64, kind string) []Edge {
+	matches := gm.edgesBetween(src, tgt, kind)
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Ordinal < matches[j].Ordinal })
+	return matches
+}
+
 // UpdateNode updates a node's properties
 func (gm *GraphManager) UpdateNode(nodeID int64, newProperties []Property) error {
 	node, err := gm.GetNode(nodeID)
 	if err != nil {
 		return fmt.Errorf("failed to get node %d: %v", nodeID, err)
 	}
+	oldProperties := node.Properties
 
 	// Merge properties
 	propMap := make(map[string]Property)
@@ -142,7 +224,19 @@ func (gm *GraphManager) UpdateNode(nodeID int64, newProperties []Property) error
 		node.Properties = append(node.Properties, p)
 	}
 
-	pageID, err := gm.recordMgr.WriteRecord(node)
+	if err := gm.indexManager.checkUnique(node.Labels, node.Properties, nodeID); err != nil {
+		return err
+	}
+	if err := gm.dispatch(nil, EventBeforeNodeUpdated, node); err != nil {
+		return err
+	}
+
+	oldRecordID, err := gm.indexManager.SearchNode(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to find node %d in index: %v", nodeID, err)
+	}
+
+	recordID, err := gm.recordMgr.WriteRecord(node)
 	if err != nil {
 		return fmt.Errorf("failed to write updated node: %v", err)
 	}
@@ -153,11 +247,22 @@ func (gm *GraphManager) UpdateNode(nodeID int64, newProperties []Property) error
 	}
 
 	// Insert new index entry
-	if err := gm.indexManager.InsertNode(nodeID, pageID); err != nil {
+	if err := gm.indexManager.InsertNode(nodeID, recordID); err != nil {
 		return fmt.Errorf("failed to update node %d in index: %v", nodeID, err)
 	}
 
-	return nil
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old node %d record: %v", nodeID, err)
+	}
+
+	gm.indexManager.unindexNode(node.Labels, oldProperties, nodeID)
+	if err := gm.indexManager.indexNode(node.Labels, node.Properties, nodeID); err != nil {
+		return fmt.Errorf("failed to reindex node %d: %v", nodeID, err)
+	}
+	gm.indexManager.unindexNodeFullText(node.Labels, oldProperties, nodeID)
+	gm.indexManager.indexNodeFullText(node.Labels, node.Properties, nodeID)
+
+	return gm.dispatch(nil, EventNodeUpdated, node)
 }
 
 // UpdateEdge updates an edge's properties
@@ -179,8 +284,16 @@ func (gm *GraphManager) UpdateEdge(edgeID int64, newProperties []Property) error
 	for _, p := range propMap {
 		edge.Properties = append(edge.Properties, p)
 	}
+	if err := gm.dispatch(nil, EventBeforeEdgeUpdated, edge); err != nil {
+		return err
+	}
+
+	oldRecordID, err := gm.indexManager.SearchEdge(edgeID)
+	if err != nil {
+		return fmt.Errorf("failed to find edge %d in index: %v", edgeID, err)
+	}
 
-	pageID, err := gm.recordMgr.WriteRecord(edge)
+	recordID, err := gm.recordMgr.WriteRecord(edge)
 	if err != nil {
 		return fmt.Errorf("failed to write updated edge: %v", err)
 	}
@@ -191,10 +304,385 @@ func (gm *GraphManager) UpdateEdge(edgeID int64, newProperties []Property) error
 	}
 
 	// Insert new index entry
-	if err := gm.indexManager.InsertEdge(edgeID, pageID); err != nil {
+	if err := gm.indexManager.InsertEdge(edgeID, recordID); err != nil {
+		return fmt.Errorf("failed to update edge %d in index: %v", edgeID, err)
+	}
+
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old edge %d record: %v", edgeID, err)
+	}
+
+	return gm.dispatch(nil, EventEdgeUpdated, edge)
+}
+
+// ReplaceNodeProperties overwrites a node's properties wholesale, unlike
+// UpdateNode's merge-by-key semantics. It exists for transaction
+// rollback: undoing a SET has to restore the exact prior property list,
+// and a merge can't remove a key that the SET introduced for the first
+// time.
+func (gm *GraphManager) ReplaceNodeProperties(nodeID int64, properties []Property) error {
+	node, err := gm.GetNode(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to get node %d: %v", nodeID, err)
+	}
+	oldProperties := node.Properties
+
+	if err := gm.indexManager.checkUnique(node.Labels, properties, nodeID); err != nil {
+		return err
+	}
+	node.Properties = properties
+
+	oldRecordID, err := gm.indexManager.SearchNode(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to find node %d in index: %v", nodeID, err)
+	}
+
+	recordID, err := gm.recordMgr.WriteRecord(node)
+	if err != nil {
+		return fmt.Errorf("failed to write restored node: %v", err)
+	}
+
+	if err := gm.indexManager.DeleteNode(nodeID); err != nil {
+		return fmt.Errorf("failed to delete old node %d index entry: %v", nodeID, err)
+	}
+	if err := gm.indexManager.InsertNode(nodeID, recordID); err != nil {
+		return fmt.Errorf("failed to update node %d in index: %v", nodeID, err)
+	}
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old node %d record: %v", nodeID, err)
+	}
+
+	gm.indexManager.unindexNode(node.Labels, oldProperties, nodeID)
+	if err := gm.indexManager.indexNode(node.Labels, node.Properties, nodeID); err != nil {
+		return fmt.Errorf("failed to reindex node %d: %v", nodeID, err)
+	}
+	gm.indexManager.unindexNodeFullText(node.Labels, oldProperties, nodeID)
+	gm.indexManager.indexNodeFullText(node.Labels, node.Properties, nodeID)
+	return nil
+}
+
+// ReplaceEdgeProperties is ReplaceNodeProperties for edges.
+func (gm *GraphManager) ReplaceEdgeProperties(edgeID int64, properties []Property) error {
+	edge, err := gm.GetEdge(edgeID)
+	if err != nil {
+		return fmt.Errorf("failed to get edge %d: %v", edgeID, err)
+	}
+	edge.Properties = properties
+
+	oldRecordID, err := gm.indexManager.SearchEdge(edgeID)
+	if err != nil {
+		return fmt.Errorf("failed to find edge %d in index: %v", edgeID, err)
+	}
+
+	recordID, err := gm.recordMgr.WriteRecord(edge)
+	if err != nil {
+		return fmt.Errorf("failed to write restored edge: %v", err)
+	}
+
+	if err := gm.indexManager.DeleteEdge(edgeID); err != nil {
+		return fmt.Errorf("failed to delete old edge %d index entry: %v", edgeID, err)
+	}
+	if err := gm.indexManager.InsertEdge(edgeID, recordID); err != nil {
 		return fmt.Errorf("failed to update edge %d in index: %v", edgeID, err)
 	}
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old edge %d record: %v", edgeID, err)
+	}
+	return nil
+}
+
+// lockForWeight returns the mutex guarding weight updates keyed by key
+// (e.g. "node:<id>"), creating it if necessary.
+func (gm *GraphManager) lockForWeight(key string) *sync.Mutex {
+	gm.weightLocksMu.Lock()
+	defer gm.weightLocksMu.Unlock()
+	l, exists := gm.weightLocks[key]
+	if !exists {
+		l = &sync.Mutex{}
+		gm.weightLocks[key] = l
+	}
+	return l
+}
+
+// lockVertices locks the weight mutex for every distinct ID in ids, in
+// ascending order, so two calls sharing any of the same node IDs always
+// acquire them in the same order and can't deadlock against each other --
+// this is the "transaction-group lock on the involved vertex IDs"
+// IncrementEdgeWeight and UpsertEdgeWeight take before mutating, since an
+// edge's weight update touches both its endpoints' consistency. Callers
+// must pass the result to unlockVertices when done.
+func (gm *GraphManager) lockVertices(ids ...int64) []*sync.Mutex {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var locks []*sync.Mutex
+	seen := make(map[int64]bool)
+	for _, id := range sorted {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		lock := gm.lockForWeight(fmt.Sprintf("node:%d", id))
+		lock.Lock()
+		locks = append(locks, lock)
+	}
+	return locks
+}
 
+func unlockVertices(locks []*sync.Mutex) {
+	for _, l := range locks {
+		l.Unlock()
+	}
+}
+
+// writeNodeWeight rewrites node's full record (Weight already updated by
+// the caller) and swaps its record-manager/index entries, the same
+// index-swap ReplaceNodeProperties uses -- but it doesn't touch the
+// property indexes, since Weight isn't an indexed property.
+func (gm *GraphManager) writeNodeWeight(node Node) error {
+	oldRecordID, err := gm.indexManager.SearchNode(node.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find node %d in index: %v", node.ID, err)
+	}
+	recordID, err := gm.recordMgr.WriteRecord(node)
+	if err != nil {
+		return fmt.Errorf("failed to write node %d: %v", node.ID, err)
+	}
+	if err := gm.indexManager.DeleteNode(node.ID); err != nil {
+		return fmt.Errorf("failed to delete old node %d index entry: %v", node.ID, err)
+	}
+	if err := gm.indexManager.InsertNode(node.ID, recordID); err != nil {
+		return fmt.Errorf("failed to update node %d in index: %v", node.ID, err)
+	}
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old node %d record: %v", node.ID, err)
+	}
+	return nil
+}
+
+// writeEdgeWeight is writeNodeWeight for edges.
+func (gm *GraphManager) writeEdgeWeight(edge Edge) error {
+	oldRecordID, err := gm.indexManager.SearchEdge(edge.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find edge %d in index: %v", edge.ID, err)
+	}
+	recordID, err := gm.recordMgr.WriteRecord(edge)
+	if err != nil {
+		return fmt.Errorf("failed to write edge %d: %v", edge.ID, err)
+	}
+	if err := gm.indexManager.DeleteEdge(edge.ID); err != nil {
+		return fmt.Errorf("failed to delete old edge %d index entry: %v", edge.ID, err)
+	}
+	if err := gm.indexManager.InsertEdge(edge.ID, recordID); err != nil {
+		return fmt.Errorf("failed to update edge %d in index: %v", edge.ID, err)
+	}
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old edge %d record: %v", edge.ID, err)
+	}
+	return nil
+}
+
+// IncrementNodeWeight adds delta to nodeID's Weight and returns the new
+// total, holding nodeID's weight lock for the whole read-modify-write so
+// concurrent increments against the same node can't lose an update.
+func (gm *GraphManager) IncrementNodeWeight(nodeID int64, delta float64) (float64, error) {
+	locks := gm.lockVertices(nodeID)
+	defer unlockVertices(locks)
+
+	node, err := gm.GetNode(nodeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get node %d: %v", nodeID, err)
+	}
+	node.Weight += delta
+	if err := gm.writeNodeWeight(node); err != nil {
+		return 0, err
+	}
+	return node.Weight, gm.dispatch(nil, EventNodeUpdated, node)
+}
+
+// SetNodeWeight overwrites nodeID's Weight to exactly weight, for
+// transaction rollback undoing an IncrementNodeWeight (see
+// TransactionOperation.BeforeWeight / undoOperation). Unlike
+// IncrementNodeWeight it doesn't take nodeID's weight lock: undo always
+// runs after the transaction that made the change has already stopped
+// mutating the graph.
+func (gm *GraphManager) SetNodeWeight(nodeID int64, weight float64) error {
+	node, err := gm.GetNode(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to get node %d: %v", nodeID, err)
+	}
+	node.Weight = weight
+	return gm.writeNodeWeight(node)
+}
+
+// IncrementEdgeWeight adds delta to edgeID's Weight and returns the new
+// total. It locks both of the edge's endpoint vertices (see
+// lockVertices) rather than just the edge itself, since a batch of
+// weight updates across many edges sharing an endpoint needs to
+// serialize on that endpoint to stay consistent -- matching
+// IncrementNodeWeight and UpsertEdgeWeight's locking.
+func (gm *GraphManager) IncrementEdgeWeight(edgeID int64, delta float64) (float64, error) {
+	edge, err := gm.GetEdge(edgeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get edge %d: %v", edgeID, err)
+	}
+	locks := gm.lockVertices(edge.Source, edge.Target)
+	defer unlockVertices(locks)
+
+	// Re-read under lock: another goroutine may have updated the edge
+	// between the unlocked lookup above and acquiring its endpoint locks.
+	edge, err = gm.GetEdge(edgeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get edge %d: %v", edgeID, err)
+	}
+	edge.Weight += delta
+	if err := gm.writeEdgeWeight(edge); err != nil {
+		return 0, err
+	}
+	return edge.Weight, gm.dispatch(nil, EventEdgeUpdated, edge)
+}
+
+// SetEdgeWeight is SetNodeWeight for edges.
+func (gm *GraphManager) SetEdgeWeight(edgeID int64, weight float64) error {
+	edge, err := gm.GetEdge(edgeID)
+	if err != nil {
+		return fmt.Errorf("failed to get edge %d: %v", edgeID, err)
+	}
+	edge.Weight = weight
+	return gm.writeEdgeWeight(edge)
+}
+
+// UpsertEdgeWeight adds delta to the Weight of the active kind edge from
+// fromID to toID, creating it with Weight delta if none exists yet --
+// the labelgraph-style "upsert a weighted edge" pattern. It returns the
+// edge's ID (new or existing) and its resulting Weight. Like
+// IncrementEdgeWeight, it holds both endpoints' weight locks for the
+// whole check-then-act so a concurrent UpsertEdgeWeight against the same
+// pair can't race past the existence check.
+func (gm *GraphManager) UpsertEdgeWeight(fromID, toID int64, kind string, delta float64) (int64, float64, error) {
+	locks := gm.lockVertices(fromID, toID)
+	defer unlockVertices(locks)
+
+	existing := gm.edgesBetween(fromID, toID, kind)
+	if len(existing) == 0 {
+		edgeID, err := gm.AddEdge(Edge{Type: kind, Source: fromID, Target: toID, Weight: delta, Ordinal: UnspecifiedOrdinal})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to create edge for upsert: %v", err)
+		}
+		return edgeID, delta, nil
+	}
+
+	edge := existing[0]
+	edge.Weight += delta
+	if err := gm.writeEdgeWeight(edge); err != nil {
+		return 0, 0, err
+	}
+	return edge.ID, edge.Weight, gm.dispatch(nil, EventEdgeUpdated, edge)
+}
+
+// parseTraverseSpec splits an EliasDB-style traversal spec
+// ":<end1role>:<kind>:<end2role>:<targetkind>" into its four components.
+// Any component may be empty to mean "don't filter on this"; kind empty
+// means follow edges of every type (see IndexManager.OutgoingEdgeIDsAny).
+func parseTraverseSpec(spec string) (end1Role, kind, end2Role, targetKind string, err error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 5 || parts[0] != "" {
+		return "", "", "", "", fmt.Errorf("invalid traversal spec %q: expected \":<end1role>:<kind>:<end2role>:<targetkind>\"", spec)
+	}
+	return parts[1], parts[2], parts[3], parts[4], nil
+}
+
+// hasLabel reports whether labels contains label.
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// TraverseNode follows nodeID's outgoing edges matching spec (an
+// EliasDB-style ":<end1role>:<kind>:<end2role>:<targetkind>" string) and
+// returns the neighbor nodes and connecting edges reached, in one call --
+// the adjacency-index-backed alternative to scanning GetEdgeIDs.
+func (gm *GraphManager) TraverseNode(nodeID int64, spec string) ([]Node, []Edge, error) {
+	end1Role, kind, end2Role, targetKind, err := parseTraverseSpec(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var edgeIDs []int64
+	if kind == "" {
+		edgeIDs = gm.indexManager.OutgoingEdgeIDsAny(nodeID)
+	} else {
+		edgeIDs = gm.indexManager.OutgoingEdgeIDs(nodeID, kind)
+	}
+
+	var nodes []Node
+	var edges []Edge
+	for _, edgeID := range edgeIDs {
+		edge, err := gm.GetEdge(edgeID)
+		if err != nil {
+			continue
+		}
+		if end1Role != "" && edge.End1Role != end1Role {
+			continue
+		}
+		if end2Role != "" && edge.End2Role != end2Role {
+			continue
+		}
+		target, err := gm.GetNode(edge.Target)
+		if err != nil {
+			continue
+		}
+		if targetKind != "" && !hasLabel(target.Labels, targetKind) {
+			continue
+		}
+		nodes = append(nodes, target)
+		edges = append(edges, edge)
+	}
+	return nodes, edges, nil
+}
+
+// RestoreNode re-inserts a previously deleted node at its original ID,
+// for transaction rollback. Unlike AddNode it doesn't assign a fresh ID
+// or advance nextNodeID: DeleteNode fully removes the index entry (there
+// is no inactive-but-findable state to reactivate), so undoing a delete
+// has to recreate the record from the before-image TransactionOperation
+// captured it with, at the same ID it originally had.
+func (gm *GraphManager) RestoreNode(node Node) error {
+	node.Active = true
+
+	recordID, err := gm.recordMgr.WriteRecord(node)
+	if err != nil {
+		return fmt.Errorf("failed to write restored node: %v", err)
+	}
+	if err := gm.indexManager.InsertNode(node.ID, recordID); err != nil {
+		return fmt.Errorf("failed to insert restored node into index: %v", err)
+	}
+	if err := gm.indexManager.indexNode(node.Labels, node.Properties, node.ID); err != nil {
+		return fmt.Errorf("failed to reindex restored node: %v", err)
+	}
+	gm.indexManager.indexNodeFullText(node.Labels, node.Properties, node.ID)
+	for _, label := range node.Labels {
+		gm.nodeLabelMap[label] = append(gm.nodeLabelMap[label], node.ID)
+	}
+	return nil
+}
+
+// RestoreEdge is RestoreNode for edges: it re-inserts a previously
+// deleted edge at its original ID and re-indexes its adjacency entries.
+func (gm *GraphManager) RestoreEdge(edge Edge) error {
+	edge.Active = true
+
+	recordID, err := gm.recordMgr.WriteRecord(edge)
+	if err != nil {
+		return fmt.Errorf("failed to write restored edge: %v", err)
+	}
+	if err := gm.indexManager.InsertEdge(edge.ID, recordID); err != nil {
+		return fmt.Errorf("failed to insert restored edge into index: %v", err)
+	}
+	gm.indexManager.IndexEdgeAdjacency(edge)
 	return nil
 }
 
@@ -206,7 +694,10 @@ func (gm *GraphManager) DeleteNode(nodeID int64) error {
 	}
 
 	node.Active = false
-	_, err = gm.indexManager.SearchNode(nodeID)
+	if err := gm.dispatch(nil, EventBeforeNodeDeleted, node); err != nil {
+		return err
+	}
+	oldRecordID, err := gm.indexManager.SearchNode(nodeID)
 	if err != nil {
 		return fmt.Errorf("failed to find node %d in index: %v", nodeID, err)
 	}
@@ -218,6 +709,11 @@ func (gm *GraphManager) DeleteNode(nodeID int64) error {
 	if err := gm.indexManager.DeleteNode(nodeID); err != nil {
 		return fmt.Errorf("failed to delete node from index: %v", err)
 	}
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old node %d record: %v", nodeID, err)
+	}
+	gm.indexManager.unindexNode(node.Labels, node.Properties, nodeID)
+	gm.indexManager.unindexNodeFullText(node.Labels, node.Properties, nodeID)
 
 	// Remove from nodeLabelMap and clean up empty entries
 	for label := range gm.nodeLabelMap {
@@ -234,9 +730,151 @@ func (gm *GraphManager) DeleteNode(nodeID int64) error {
 		}
 	}
 
+	return gm.dispatch(nil, EventNodeDeleted, node)
+}
+
+// SetEdgeCascade updates an edge's cascade flags in place.
+func (gm *GraphManager) SetEdgeCascade(edgeID int64, spec CascadeSpec) error {
+	edge, err := gm.GetEdge(edgeID)
+	if err != nil {
+		return fmt.Errorf("failed to get edge %d: %v", edgeID, err)
+	}
+	edge.Cascade = spec
+
+	oldRecordID, err := gm.indexManager.SearchEdge(edgeID)
+	if err != nil {
+		return fmt.Errorf("failed to find edge %d in index: %v", edgeID, err)
+	}
+
+	recordID, err := gm.recordMgr.WriteRecord(edge)
+	if err != nil {
+		return fmt.Errorf("failed to write edge with cascade spec: %v", err)
+	}
+	if err := gm.indexManager.DeleteEdge(edgeID); err != nil {
+		return fmt.Errorf("failed to delete old edge %d index entry: %v", edgeID, err)
+	}
+	if err := gm.indexManager.InsertEdge(edgeID, recordID); err != nil {
+		return fmt.Errorf("failed to update edge %d in index: %v", edgeID, err)
+	}
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old edge %d record: %v", edgeID, err)
+	}
 	return nil
 }
 
+// incidentEdges returns every active edge touching nodeID, as either
+// source or target. It scans the whole edge index rather than the
+// outAdj/inAdj adjacency indexes, but incident-edge lookups are only on
+// the cascading-delete path, not the hot query path.
+func (gm *GraphManager) incidentEdges(nodeID int64) []Edge {
+	var edges []Edge
+	for edgeID := range gm.indexManager.edgeIndex {
+		edge, err := gm.GetEdge(edgeID)
+		if err != nil || !edge.Active {
+			continue
+		}
+		if edge.Source == nodeID || edge.Target == nodeID {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// activeEdgesToTarget returns every active edge, other than
+// excludeEdgeID, whose Target is targetID. Used to tell whether an edge
+// about to be deleted is the last one keeping targetID reachable.
+func (gm *GraphManager) activeEdgesToTarget(targetID, excludeEdgeID int64) []Edge {
+	var edges []Edge
+	for edgeID := range gm.indexManager.edgeIndex {
+		if edgeID == excludeEdgeID {
+			continue
+		}
+		edge, err := gm.GetEdge(edgeID)
+		if err != nil || !edge.Active {
+			continue
+		}
+		if edge.Target == targetID {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// activeEdgesFromSource mirrors activeEdgesToTarget for the source side.
+func (gm *GraphManager) activeEdgesFromSource(sourceID, excludeEdgeID int64) []Edge {
+	var edges []Edge
+	for edgeID := range gm.indexManager.edgeIndex {
+		if edgeID == excludeEdgeID {
+			continue
+		}
+		edge, err := gm.GetEdge(edgeID)
+		if err != nil || !edge.Active {
+			continue
+		}
+		if edge.Source == sourceID {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// DeleteNodeCascade deletes nodeID, its incident edges, and -- following
+// each incident edge's CascadeSpec -- any other node that should be
+// cleaned up alongside it. It returns every node and edge actually
+// deleted, captured just before each delete, so the caller can log a
+// full before-image for transaction rollback. visited guards against
+// cascade cycles (e.g. A and B both marked CascadeToTarget on the edge
+// pointing at the other).
+func (gm *GraphManager) DeleteNodeCascade(nodeID int64, visited map[int64]bool) (deletedNodes []Node, deletedEdges []Edge, err error) {
+	if visited[nodeID] {
+		return nil, nil, nil
+	}
+	visited[nodeID] = true
+
+	node, err := gm.GetNode(nodeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get node %d: %v", nodeID, err)
+	}
+	incident := gm.incidentEdges(nodeID)
+
+	if err := gm.DeleteNode(nodeID); err != nil {
+		return nil, nil, fmt.Errorf("failed to delete node %d: %v", nodeID, err)
+	}
+	deletedNodes = append(deletedNodes, node)
+
+	for _, edge := range incident {
+		var cascadeTo int64
+		cascade := false
+
+		switch {
+		case edge.Source == nodeID && edge.Cascade.CascadeToTarget:
+			cascadeTo, cascade = edge.Target, true
+		case edge.Source == nodeID && edge.Cascade.CascadeLastToTarget && len(gm.activeEdgesToTarget(edge.Target, edge.ID)) == 0:
+			cascadeTo, cascade = edge.Target, true
+		case edge.Target == nodeID && edge.Cascade.CascadeFromTarget:
+			cascadeTo, cascade = edge.Source, true
+		case edge.Target == nodeID && edge.Cascade.CascadeLastFromTarget && len(gm.activeEdgesFromSource(edge.Source, edge.ID)) == 0:
+			cascadeTo, cascade = edge.Source, true
+		}
+
+		if err := gm.DeleteEdge(edge.ID); err != nil {
+			return deletedNodes, deletedEdges, fmt.Errorf("failed to delete incident edge %d: %v", edge.ID, err)
+		}
+		deletedEdges = append(deletedEdges, edge)
+
+		if cascade && !visited[cascadeTo] {
+			moreNodes, moreEdges, err := gm.DeleteNodeCascade(cascadeTo, visited)
+			deletedNodes = append(deletedNodes, moreNodes...)
+			deletedEdges = append(deletedEdges, moreEdges...)
+			if err != nil {
+				return deletedNodes, deletedEdges, err
+			}
+		}
+	}
+
+	return deletedNodes, deletedEdges, nil
+}
+
 // DeleteEdge marks an edge as inactive
 func (gm *GraphManager) DeleteEdge(edgeID int64) error {
 	edge, err := gm.GetEdge(edgeID)
@@ -245,7 +883,10 @@ func (gm *GraphManager) DeleteEdge(edgeID int64) error {
 	}
 
 	edge.Active = false
-	_, err = gm.indexManager.SearchEdge(edgeID)
+	if err := gm.dispatch(nil, EventBeforeEdgeDeleted, edge); err != nil {
+		return err
+	}
+	oldRecordID, err := gm.indexManager.SearchEdge(edgeID)
 	if err != nil {
 		return fmt.Errorf("failed to find edge %d in index: %v", edgeID, err)
 	}
@@ -257,6 +898,30 @@ func (gm *GraphManager) DeleteEdge(edgeID int64) error {
 	if err := gm.indexManager.DeleteEdge(edgeID); err != nil {
 		return fmt.Errorf("failed to delete edge from index: %v", err)
 	}
+	gm.indexManager.UnindexEdgeAdjacency(edge)
+	if err := gm.recordMgr.DeleteRecord(oldRecordID); err != nil {
+		return fmt.Errorf("failed to reclaim old edge %d record: %v", edgeID, err)
+	}
+
+	return gm.dispatch(nil, EventEdgeDeleted, edge)
+}
 
+// RebuildAdjacencyIndex materializes the forward/reverse adjacency
+// indices from every edge currently in the index. It exists for legacy
+// data whose edges were inserted before adjacency tracking existed (e.g.
+// an old CSV/GraphML import) and is safe to run as a background
+// maintenance job: it discards and replaces the existing adjacency maps
+// rather than appending, so re-running it is harmless.
+func (gm *GraphManager) RebuildAdjacencyIndex() error {
+	gm.indexManager.ResetAdjacency()
+	for edgeID := range gm.indexManager.edgeIndex {
+		edge, err := gm.GetEdge(edgeID)
+		if err != nil {
+			continue
+		}
+		if edge.Active {
+			gm.indexManager.IndexEdgeAdjacency(edge)
+		}
+	}
 	return nil
 }
@@ -2,56 +2,83 @@ package graphdb
 
 import (
 	"fmt"
+	"strings"
 )
 
 // IndexManager handles indexing for nodes and edges
 type IndexManager struct {
-	nodeIndex map[int64]int // nodeID -> pageID
-	edgeIndex map[int64]int // edgeID -> pageID
+	nodeIndex map[int64]RecordID // nodeID -> RecordID
+	edgeIndex map[int64]RecordID // edgeID -> RecordID
+
+	// outAdj/inAdj are the forward and reverse adjacency indices: nodeID
+	// -> edge type -> edge IDs leaving (outAdj) or arriving at (inAdj)
+	// that node. They let executeMatch resolve a directed pattern
+	// anchored on a bound node in O(deg) instead of scanning edgeIndex.
+	outAdj map[int64]map[string][]int64
+	inAdj  map[int64]map[string][]int64
+
+	// edgeTypeCounts is the third adjacency-related index: edge type ->
+	// number of active edges of that type, kept in step with
+	// outAdj/inAdj by IndexEdgeAdjacency/UnindexEdgeAdjacency/ResetAdjacency
+	// so EdgeCount is O(1) instead of a GetEdgeIDs scan.
+	edgeTypeCounts map[string]int
+
+	// properties holds every secondary index created by CreateIndex,
+	// keyed by IndexDef.key() ("Label.propA.propB"). See property_index.go.
+	properties map[string]*propertyIndex
+
+	// fulltext holds every inverted index created by CreateFullTextIndex,
+	// keyed by FullTextIndexDef.key() ("Label.propKey"). See fulltext.go.
+	fulltext map[string]*fullTextIndex
 }
 
 // NewIndexManager initializes a new IndexManager
 func NewIndexManager() *IndexManager {
 	return &IndexManager{
-		nodeIndex: make(map[int64]int),
-		edgeIndex: make(map[int64]int),
+		nodeIndex:      make(map[int64]RecordID),
+		edgeIndex:      make(map[int64]RecordID),
+		outAdj:         make(map[int64]map[string][]int64),
+		inAdj:          make(map[int64]map[string][]int64),
+		edgeTypeCounts: make(map[string]int),
+		properties:     make(map[string]*propertyIndex),
+		fulltext:       make(map[string]*fullTextIndex),
 	}
 }
 
 // InsertNode adds a node to the index
-func (im *IndexManager) InsertNode(nodeID int64, pageID int) error {
+func (im *IndexManager) InsertNode(nodeID int64, id RecordID) error {
 	if _, exists := im.nodeIndex[nodeID]; exists {
 		return fmt.Errorf("node ID %d already exists", nodeID)
 	}
-	im.nodeIndex[nodeID] = pageID
+	im.nodeIndex[nodeID] = id
 	return nil
 }
 
 // InsertEdge adds an edge to the index
-func (im *IndexManager) InsertEdge(edgeID int64, pageID int) error {
+func (im *IndexManager) InsertEdge(edgeID int64, id RecordID) error {
 	if _, exists := im.edgeIndex[edgeID]; exists {
 		return fmt.Errorf("edge ID %d already exists", edgeID)
 	}
-	im.edgeIndex[edgeID] = pageID
+	im.edgeIndex[edgeID] = id
 	return nil
 }
 
-// SearchNode retrieves the page ID for a node
-func (im *IndexManager) SearchNode(nodeID int64) (int, error) {
-	pageID, exists := im.nodeIndex[nodeID]
+// SearchNode retrieves the RecordID for a node
+func (im *IndexManager) SearchNode(nodeID int64) (RecordID, error) {
+	id, exists := im.nodeIndex[nodeID]
 	if !exists {
-		return -1, fmt.Errorf("node ID %d not found", nodeID)
+		return RecordID{}, fmt.Errorf("node ID %d not found", nodeID)
 	}
-	return pageID, nil
+	return id, nil
 }
 
-// SearchEdge retrieves the page ID for an edge
-func (im *IndexManager) SearchEdge(edgeID int64) (int, error) {
-	pageID, exists := im.edgeIndex[edgeID]
+// SearchEdge retrieves the RecordID for an edge
+func (im *IndexManager) SearchEdge(edgeID int64) (RecordID, error) {
+	id, exists := im.edgeIndex[edgeID]
 	if !exists {
-		return -1, fmt.Errorf("edge ID %d not found", edgeID)
+		return RecordID{}, fmt.Errorf("edge ID %d not found", edgeID)
 	}
-	return pageID, nil
+	return id, nil
 }
 
 // DeleteNode removes a node from the index
@@ -80,3 +107,107 @@ func (im *IndexManager) GetEdgeIDs() []int64 {
 	}
 	return ids
 }
+
+// GetNodeIDs returns all node IDs in the index, regardless of label.
+func (im *IndexManager) GetNodeIDs() []int64 {
+	ids := make([]int64, 0, len(im.nodeIndex))
+	for id := range im.nodeIndex {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// IndexEdgeAdjacency records edge in both the forward adjacency index
+// (keyed on its source) and the reverse adjacency index (keyed on its
+// target).
+func (im *IndexManager) IndexEdgeAdjacency(edge Edge) {
+	if im.outAdj[edge.Source] == nil {
+		im.outAdj[edge.Source] = make(map[string][]int64)
+	}
+	im.outAdj[edge.Source][edge.Type] = append(im.outAdj[edge.Source][edge.Type], edge.ID)
+
+	if im.inAdj[edge.Target] == nil {
+		im.inAdj[edge.Target] = make(map[string][]int64)
+	}
+	im.inAdj[edge.Target][edge.Type] = append(im.inAdj[edge.Target][edge.Type], edge.ID)
+
+	im.edgeTypeCounts[edge.Type]++
+}
+
+// UnindexEdgeAdjacency removes edge from the forward/reverse adjacency
+// indices, e.g. once it has been deleted.
+func (im *IndexManager) UnindexEdgeAdjacency(edge Edge) {
+	im.outAdj[edge.Source][edge.Type] = removeEdgeID(im.outAdj[edge.Source][edge.Type], edge.ID)
+	im.inAdj[edge.Target][edge.Type] = removeEdgeID(im.inAdj[edge.Target][edge.Type], edge.ID)
+
+	if im.edgeTypeCounts[edge.Type] > 0 {
+		im.edgeTypeCounts[edge.Type]--
+	}
+}
+
+// ResetAdjacency discards the forward/reverse adjacency indices, and the
+// edge-type counts they back, so they can be rebuilt from scratch (see
+// GraphManager.RebuildAdjacencyIndex).
+func (im *IndexManager) ResetAdjacency() {
+	im.outAdj = make(map[int64]map[string][]int64)
+	im.inAdj = make(map[int64]map[string][]int64)
+	im.edgeTypeCounts = make(map[string]int)
+}
+
+// OutgoingEdgeIDs returns the IDs of edges of edgeType leaving nodeID,
+// via the forward adjacency index.
+func (im *IndexManager) OutgoingEdgeIDs(nodeID int64, edgeType string) []int64 {
+	ids := im.outAdj[nodeID][edgeType]
+	out := make([]int64, len(ids))
+	copy(out, ids)
+	return out
+}
+
+// OutgoingEdgeIDsAny returns the IDs of every active edge leaving nodeID,
+// regardless of type -- the wildcard-kind case of
+// GraphManager.TraverseNode's EliasDB-style spec string.
+func (im *IndexManager) OutgoingEdgeIDsAny(nodeID int64) []int64 {
+	var out []int64
+	for _, ids := range im.outAdj[nodeID] {
+		out = append(out, ids...)
+	}
+	return out
+}
+
+// EdgeCount returns the number of active edges of kind, via the
+// edge-type-count index.
+func (im *IndexManager) EdgeCount(kind string) int {
+	return im.edgeTypeCounts[kind]
+}
+
+// IncomingEdgeIDs returns the IDs of edges of edgeType arriving at
+// nodeID, via the reverse adjacency index.
+func (im *IndexManager) IncomingEdgeIDs(nodeID int64, edgeType string) []int64 {
+	ids := im.inAdj[nodeID][edgeType]
+	out := make([]int64, len(ids))
+	copy(out, ids)
+	return out
+}
+
+// removeEdgeID returns ids with target removed, preserving order.
+func removeEdgeID(ids []int64, target int64) []int64 {
+	out := ids[:0]
+	for _, id := range ids {
+		if id != target {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// MirrorKind returns the conventional name for kind's reverse
+// relationship -- the naming graph stores use to refer to "the same edge,
+// seen from the other end" in a reverse-edge view (EliasDB calls this a
+// mirrored edge): kind prefixed with "~", or kind itself with the prefix
+// stripped if it already has one.
+func MirrorKind(kind string) string {
+	if strings.HasPrefix(kind, "~") {
+		return strings.TrimPrefix(kind, "~")
+	}
+	return "~" + kind
+}
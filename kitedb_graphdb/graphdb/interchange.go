@@ -0,0 +1,601 @@
+package graphdb
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Exporter writes every active node and edge in a GraphDB to w in some
+// interchange format. Implementations stream through w rather than
+// building the whole document in memory, so export works on databases
+// larger than RAM.
+type Exporter interface {
+	Export(db *GraphDB, w io.Writer) error
+}
+
+// Importer reads nodes and edges from r and inserts them into a GraphDB.
+// Implementations batch their inserts through a single transaction so a
+// large import is one WAL-logged unit instead of one per record.
+type Importer interface {
+	Import(db *GraphDB, r io.Reader) error
+}
+
+// ExporterFor returns the Exporter registered for format ("graphml",
+// "csv", or "ndjson"), or an error if format is unknown.
+func ExporterFor(format string) (Exporter, error) {
+	switch format {
+	case "graphml":
+		return GraphMLExporter{}, nil
+	case "csv":
+		return CSVExporter{}, nil
+	case "ndjson":
+		return NDJSONExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// ImporterFor returns the Importer registered for format ("graphml",
+// "csv", or "ndjson"), or an error if format is unknown.
+func ImporterFor(format string) (Importer, error) {
+	switch format {
+	case "graphml":
+		return GraphMLImporter{}, nil
+	case "csv":
+		return CSVImporter{}, nil
+	case "ndjson":
+		return NDJSONImporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+}
+
+// propertyValueString renders a Property's value the way interchange
+// formats expect it: as plain text.
+func propertyValueString(p Property) string {
+	return fmt.Sprintf("%v", p.Value)
+}
+
+// parseProperty rebuilds a typed Property from a string value, inferring
+// PropertyBool/PropertyInt/PropertyString the way literals are inferred
+// elsewhere in the package (int64 for whole numbers, bool for true/false,
+// string otherwise).
+func parseProperty(key, raw string) Property {
+	if raw == "true" || raw == "false" {
+		return Property{Key: key, Value: raw == "true", Type: PropertyBool}
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return Property{Key: key, Value: n, Type: PropertyInt}
+	}
+	return Property{Key: key, Value: raw, Type: PropertyString}
+}
+
+// importBatch runs insert against db inside a single transaction,
+// matching the batching pattern used elsewhere (see Tx): all of an
+// import's writes share one WAL-logged unit instead of committing one
+// record at a time.
+func importBatch(db *GraphDB, insert func(txnID int64) error) error {
+	txnID := db.txnMgr.BeginTransaction()
+	if err := insert(txnID); err != nil {
+		return err
+	}
+	if err := db.txnMgr.CommitTransaction(txnID); err != nil {
+		return fmt.Errorf("failed to commit import transaction: %v", err)
+	}
+	return nil
+}
+
+// ---- NDJSON ----
+
+// ndjsonRecord is the shape of a single line in the newline-delimited
+// JSON interchange format: either a node or an edge, distinguished by
+// Kind.
+type ndjsonRecord struct {
+	Kind       string            `json:"kind"` // "node" or "edge"
+	ID         int64             `json:"id"`
+	Labels     []string          `json:"labels,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	Source     int64             `json:"source,omitempty"`
+	Target     int64             `json:"target,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+func propertiesToMap(props []Property) map[string]string {
+	if len(props) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(props))
+	for _, p := range props {
+		m[p.Key] = propertyValueString(p)
+	}
+	return m
+}
+
+// NDJSONExporter writes one JSON document per line, node or edge.
+type NDJSONExporter struct{}
+
+// Export writes one record per node, then per edge, as it's fetched from
+// indexMgr's ID lists -- json.Encoder writes straight through to w on
+// every Encode call, so no more than one node/edge is ever in memory at
+// once, and export works on a graph larger than RAM.
+func (NDJSONExporter) Export(db *GraphDB, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, id := range db.indexMgr.GetNodeIDs() {
+		n, err := db.graph.GetNode(id)
+		if err != nil {
+			continue
+		}
+		if err := enc.Encode(ndjsonRecord{
+			Kind:       "node",
+			ID:         n.ID,
+			Labels:     n.Labels,
+			Properties: propertiesToMap(n.Properties),
+		}); err != nil {
+			return fmt.Errorf("failed to write node %d: %v", n.ID, err)
+		}
+	}
+	for _, id := range db.indexMgr.GetEdgeIDs() {
+		e, err := db.graph.GetEdge(id)
+		if err != nil {
+			continue
+		}
+		if err := enc.Encode(ndjsonRecord{
+			Kind:       "edge",
+			ID:         e.ID,
+			Type:       e.Type,
+			Source:     e.Source,
+			Target:     e.Target,
+			Properties: propertiesToMap(e.Properties),
+		}); err != nil {
+			return fmt.Errorf("failed to write edge %d: %v", e.ID, err)
+		}
+	}
+	return nil
+}
+
+// NDJSONImporter reads the format written by NDJSONExporter.
+type NDJSONImporter struct{}
+
+func (NDJSONImporter) Import(db *GraphDB, r io.Reader) error {
+	return importBatch(db, func(txnID int64) error {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			var rec ndjsonRecord
+			if err := json.Unmarshal(line, &rec); err != nil {
+				return fmt.Errorf("failed to parse ndjson line: %v", err)
+			}
+
+			props := make([]Property, 0, len(rec.Properties))
+			for k, v := range rec.Properties {
+				props = append(props, parseProperty(k, v))
+			}
+
+			switch rec.Kind {
+			case "node":
+				nodeID, err := db.graph.AddNode(Node{Labels: rec.Labels, Properties: props})
+				if err != nil {
+					return fmt.Errorf("failed to import node: %v", err)
+				}
+				if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpAddNode, NodeID: nodeID}); err != nil {
+					return err
+				}
+			case "edge":
+				edgeID, err := db.graph.AddEdge(Edge{Type: rec.Type, Source: rec.Source, Target: rec.Target, Properties: props, Ordinal: UnspecifiedOrdinal})
+				if err != nil {
+					return fmt.Errorf("failed to import edge: %v", err)
+				}
+				if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpAddEdge, EdgeID: edgeID}); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("ndjson line has unknown kind %q", rec.Kind)
+			}
+		}
+		return scanner.Err()
+	})
+}
+
+// ---- CSV ----
+
+// CSVExporter writes two CSV tables back to back, Neo4j-admin-import
+// style: a `:NODE` header row followed by every node, then an `:EDGE`
+// header row followed by every edge. Properties are flattened into a
+// single `properties` column as `key=value` pairs separated by `;`,
+// since node/edge property sets aren't uniform across a database and a
+// fixed column layout can't be known ahead of time.
+type CSVExporter struct{}
+
+// csvPropertyEscaper percent-encodes the three bytes flattenProperties
+// uses as delimiters (plus '%' itself, so the encoding is unambiguous to
+// reverse) out of a property key or value, the same idea as net/url's
+// query escaping but scoped to just the bytes this format actually needs
+// protected -- a value like "a;b" no longer gets mistaken for a second
+// pair, and "=" no longer gets mistaken for the key/value separator.
+var csvPropertyEscaper = strings.NewReplacer(
+	"%", "%25",
+	";", "%3B",
+	"=", "%3D",
+	"\n", "%0A",
+)
+
+func csvEscapeProperty(s string) string {
+	return csvPropertyEscaper.Replace(s)
+}
+
+// csvUnescapeProperty reverses csvEscapeProperty, erroring on a malformed
+// (truncated or non-hex) %-escape instead of silently passing it through.
+func csvUnescapeProperty(s string) (string, error) {
+	if !strings.ContainsRune(s, '%') {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("truncated %%-escape in %q", s)
+		}
+		n, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid %%-escape %q: %v", s[i:i+3], err)
+		}
+		b.WriteByte(byte(n))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// flattenProperties joins props into the single `properties` CSV column
+// as `key=value` pairs separated by `;`, percent-encoding `;`/`=`/`%`/
+// newline out of each key and value first so unflattenProperties can
+// split and un-escape it unambiguously.
+func flattenProperties(props []Property) string {
+	parts := make([]string, 0, len(props))
+	for _, p := range props {
+		parts = append(parts, csvEscapeProperty(p.Key)+"="+csvEscapeProperty(propertyValueString(p)))
+	}
+	return strings.Join(parts, ";")
+}
+
+// unflattenProperties reverses flattenProperties, erroring on a pair with
+// no `=` (or a malformed %-escape) rather than silently dropping it.
+func unflattenProperties(raw string) ([]Property, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var props []Property
+	for _, pair := range strings.Split(raw, ";") {
+		if pair == "" {
+			continue
+		}
+		idx := strings.IndexByte(pair, '=')
+		if idx < 0 {
+			return nil, fmt.Errorf("malformed property pair %q: missing '='", pair)
+		}
+		key, err := csvUnescapeProperty(pair[:idx])
+		if err != nil {
+			return nil, fmt.Errorf("property key %q: %v", pair[:idx], err)
+		}
+		value, err := csvUnescapeProperty(pair[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("property value for key %q: %v", key, err)
+		}
+		props = append(props, parseProperty(key, value))
+	}
+	return props, nil
+}
+
+// Export writes each node, then each edge, as a CSV record as soon as
+// it's fetched from indexMgr's ID lists -- csv.Writer's internal
+// bufio.Writer flushes to w whenever its buffer fills, so no more than a
+// handful of records are ever held at once, and export works on a graph
+// larger than RAM.
+func (CSVExporter) Export(db *GraphDB, w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{":NODE"}); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{"id:ID", "labels", "properties"}); err != nil {
+		return err
+	}
+	for _, id := range db.indexMgr.GetNodeIDs() {
+		n, err := db.graph.GetNode(id)
+		if err != nil {
+			continue
+		}
+		if err := cw.Write([]string{strconv.FormatInt(n.ID, 10), strings.Join(n.Labels, ";"), flattenProperties(n.Properties)}); err != nil {
+			return fmt.Errorf("failed to write node %d: %v", n.ID, err)
+		}
+	}
+
+	if err := cw.Write([]string{":EDGE"}); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{":START_ID", ":END_ID", ":TYPE", "properties"}); err != nil {
+		return err
+	}
+	for _, id := range db.indexMgr.GetEdgeIDs() {
+		e, err := db.graph.GetEdge(id)
+		if err != nil {
+			continue
+		}
+		if err := cw.Write([]string{strconv.FormatInt(e.Source, 10), strconv.FormatInt(e.Target, 10), e.Type, flattenProperties(e.Properties)}); err != nil {
+			return fmt.Errorf("failed to write edge %d: %v", e.ID, err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// CSVImporter reads the format written by CSVExporter.
+type CSVImporter struct{}
+
+func (CSVImporter) Import(db *GraphDB, r io.Reader) error {
+	return importBatch(db, func(txnID int64) error {
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+
+		section := ""
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read csv record: %v", err)
+			}
+
+			switch record[0] {
+			case ":NODE", ":EDGE":
+				section = record[0]
+				continue
+			case "id:ID", ":START_ID":
+				continue // header row
+			}
+
+			switch section {
+			case ":NODE":
+				var labels []string
+				if record[1] != "" {
+					labels = strings.Split(record[1], ";")
+				}
+				props, err := unflattenProperties(record[2])
+				if err != nil {
+					return fmt.Errorf("failed to parse node properties: %v", err)
+				}
+				nodeID, err := db.graph.AddNode(Node{Labels: labels, Properties: props})
+				if err != nil {
+					return fmt.Errorf("failed to import node: %v", err)
+				}
+				if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpAddNode, NodeID: nodeID}); err != nil {
+					return err
+				}
+			case ":EDGE":
+				source, err := strconv.ParseInt(record[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("failed to parse edge source id: %v", err)
+				}
+				target, err := strconv.ParseInt(record[1], 10, 64)
+				if err != nil {
+					return fmt.Errorf("failed to parse edge target id: %v", err)
+				}
+				props, err := unflattenProperties(record[3])
+				if err != nil {
+					return fmt.Errorf("failed to parse edge properties: %v", err)
+				}
+				edgeID, err := db.graph.AddEdge(Edge{Type: record[2], Source: source, Target: target, Properties: props, Ordinal: UnspecifiedOrdinal})
+				if err != nil {
+					return fmt.Errorf("failed to import edge: %v", err)
+				}
+				if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpAddEdge, EdgeID: edgeID}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ---- GraphML ----
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlGraph struct {
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+// GraphML property keys used for metadata that isn't a user property.
+const (
+	graphmlKeyLabels = "labels"
+	graphmlKeyType   = "type"
+)
+
+// GraphMLExporter writes the graph as a single GraphML document,
+// following the format Gephi and Cytoscape both read natively. Node
+// labels and edge types are stored as `data` elements alongside
+// properties, using reserved key names ("labels", "type").
+type GraphMLExporter struct{}
+
+// Export writes the `<graphml>`/`<graph>` wrapper via EncodeToken and each
+// node/edge via EncodeElement as soon as it's fetched from indexMgr's ID
+// lists, rather than building one complete graphmlDoc in memory first --
+// EncodeElement still honors graphmlNode/graphmlEdge's own field tags, it
+// just lets the caller name the outer element, so no more than one
+// node/edge is ever held in memory at once and export works on a graph
+// larger than RAM.
+func (GraphMLExporter) Export(db *GraphDB, w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write graphml header: %v", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	graphmlStart := xml.StartElement{Name: xml.Name{Local: "graphml"}}
+	if err := enc.EncodeToken(graphmlStart); err != nil {
+		return fmt.Errorf("failed to write graphml element: %v", err)
+	}
+	graphStart := xml.StartElement{
+		Name: xml.Name{Local: "graph"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "edgedefault"}, Value: "directed"}},
+	}
+	if err := enc.EncodeToken(graphStart); err != nil {
+		return fmt.Errorf("failed to write graph element: %v", err)
+	}
+
+	nodeStart := xml.StartElement{Name: xml.Name{Local: "node"}}
+	for _, id := range db.indexMgr.GetNodeIDs() {
+		n, err := db.graph.GetNode(id)
+		if err != nil {
+			continue
+		}
+		gn := graphmlNode{ID: strconv.FormatInt(n.ID, 10)}
+		if len(n.Labels) > 0 {
+			gn.Data = append(gn.Data, graphmlData{Key: graphmlKeyLabels, Value: strings.Join(n.Labels, ";")})
+		}
+		for _, p := range n.Properties {
+			gn.Data = append(gn.Data, graphmlData{Key: p.Key, Value: propertyValueString(p)})
+		}
+		if err := enc.EncodeElement(gn, nodeStart); err != nil {
+			return fmt.Errorf("failed to write node %d: %v", n.ID, err)
+		}
+	}
+
+	edgeStart := xml.StartElement{Name: xml.Name{Local: "edge"}}
+	for _, id := range db.indexMgr.GetEdgeIDs() {
+		e, err := db.graph.GetEdge(id)
+		if err != nil {
+			continue
+		}
+		ge := graphmlEdge{
+			Source: strconv.FormatInt(e.Source, 10),
+			Target: strconv.FormatInt(e.Target, 10),
+			Data:   []graphmlData{{Key: graphmlKeyType, Value: e.Type}},
+		}
+		for _, p := range e.Properties {
+			ge.Data = append(ge.Data, graphmlData{Key: p.Key, Value: propertyValueString(p)})
+		}
+		if err := enc.EncodeElement(ge, edgeStart); err != nil {
+			return fmt.Errorf("failed to write edge %d: %v", e.ID, err)
+		}
+	}
+
+	if err := enc.EncodeToken(graphStart.End()); err != nil {
+		return fmt.Errorf("failed to close graph element: %v", err)
+	}
+	if err := enc.EncodeToken(graphmlStart.End()); err != nil {
+		return fmt.Errorf("failed to close graphml element: %v", err)
+	}
+	return enc.Flush()
+}
+
+// GraphMLImporter reads the format written by GraphMLExporter. Node IDs
+// from the source file are not preserved -- AddNode/AddEdge always
+// assign fresh IDs -- so edges are resolved against the source file's
+// IDs via an in-memory remap built while importing nodes.
+type GraphMLImporter struct{}
+
+func (GraphMLImporter) Import(db *GraphDB, r io.Reader) error {
+	var doc graphmlDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return fmt.Errorf("failed to parse graphml document: %v", err)
+	}
+
+	return importBatch(db, func(txnID int64) error {
+		idRemap := make(map[string]int64, len(doc.Graph.Nodes))
+
+		for _, gn := range doc.Graph.Nodes {
+			var labels []string
+			props := make([]Property, 0, len(gn.Data))
+			for _, d := range gn.Data {
+				if d.Key == graphmlKeyLabels {
+					start := 0
+					for i := 0; i <= len(d.Value); i++ {
+						if i == len(d.Value) || d.Value[i] == ';' {
+							labels = append(labels, d.Value[start:i])
+							start = i + 1
+						}
+					}
+					continue
+				}
+				props = append(props, parseProperty(d.Key, d.Value))
+			}
+
+			nodeID, err := db.graph.AddNode(Node{Labels: labels, Properties: props})
+			if err != nil {
+				return fmt.Errorf("failed to import node %q: %v", gn.ID, err)
+			}
+			if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpAddNode, NodeID: nodeID}); err != nil {
+				return err
+			}
+			idRemap[gn.ID] = nodeID
+		}
+
+		for _, ge := range doc.Graph.Edges {
+			source, ok := idRemap[ge.Source]
+			if !ok {
+				return fmt.Errorf("edge references unknown source node %q", ge.Source)
+			}
+			target, ok := idRemap[ge.Target]
+			if !ok {
+				return fmt.Errorf("edge references unknown target node %q", ge.Target)
+			}
+
+			edgeType := ""
+			props := make([]Property, 0, len(ge.Data))
+			for _, d := range ge.Data {
+				if d.Key == graphmlKeyType {
+					edgeType = d.Value
+					continue
+				}
+				props = append(props, parseProperty(d.Key, d.Value))
+			}
+
+			edgeID, err := db.graph.AddEdge(Edge{Type: edgeType, Source: source, Target: target, Properties: props, Ordinal: UnspecifiedOrdinal})
+			if err != nil {
+				return fmt.Errorf("failed to import edge %s->%s: %v", ge.Source, ge.Target, err)
+			}
+			if err := db.txnMgr.RecordOperation(txnID, TransactionOperation{Type: OpAddEdge, EdgeID: edgeID}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,142 @@
+package graphdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// MsgPackEncoder renders results as a MessagePack array of row maps,
+// mirroring BSONEncoder's shape (a "_kind" discriminator on every
+// node/edge, typed properties) so either wire format round-trips the same
+// way for a client.
+type MsgPackEncoder struct{}
+
+// Encode implements Encoder.
+func (MsgPackEncoder) Encode(results []map[string]interface{}) ([]byte, error) {
+	rows, err := msgpackRows(results)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := msgpackWrite(&buf, rows); err != nil {
+		return nil, fmt.Errorf("failed to encode results as MsgPack: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// msgpackRows converts result rows into a generic, MsgPack-friendly tree
+// of map[string]interface{} / []interface{} / string / int64 / bool.
+func msgpackRows(results []map[string]interface{}) ([]interface{}, error) {
+	rows := make([]interface{}, 0, len(results))
+	for _, row := range results {
+		out := make(map[string]interface{}, len(row))
+		for varName, item := range row {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unexpected result shape for %q", varName)
+			}
+
+			rendered := map[string]interface{}{}
+			if _, isEdge := itemMap["type"]; isEdge {
+				rendered["_kind"] = "edge"
+				rendered["id"] = itemMap["id"]
+				rendered["type"] = itemMap["type"]
+				rendered["source"] = itemMap["source"]
+				rendered["target"] = itemMap["target"]
+			} else {
+				rendered["_kind"] = "node"
+				rendered["id"] = itemMap["id"]
+				labels, _ := itemMap["labels"].([]string)
+				labelList := make([]interface{}, len(labels))
+				for i, label := range labels {
+					labelList[i] = label
+				}
+				rendered["labels"] = labelList
+			}
+
+			props, _ := itemMap["properties"].([]Property)
+			propList := make([]interface{}, len(props))
+			for i, prop := range props {
+				// prop.Value's concrete Go type already matches one of
+				// msgpackWrite's cases for every PropertyType (see
+				// inferPropertyType in utils.go); msgpackWrite reports an
+				// error itself if a future PropertyType adds a type it
+				// doesn't yet handle.
+				propList[i] = map[string]interface{}{"key": prop.Key, "value": prop.Value}
+			}
+			rendered["properties"] = propList
+
+			out[varName] = rendered
+		}
+		rows = append(rows, out)
+	}
+	return rows, nil
+}
+
+// msgpackWrite encodes a generic value (string, bool, int64, float64,
+// time.Time, []byte, []interface{}, map[string]interface{}, or nil) per
+// the MessagePack spec. Every case always picks the spec's largest fixed
+// format for its type (int64, str32, bin32, float64) rather than the
+// smaller variable-width ones, trading wire size for one code path per
+// type -- the same tradeoff the pre-existing int64/string cases made.
+func msgpackWrite(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if val {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, val)
+	case float64:
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, val)
+	case string:
+		if len(val) > 0xffffffff {
+			return fmt.Errorf("string too long for MsgPack: %d bytes", len(val))
+		}
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(len(val)))
+		buf.WriteString(val)
+	case []byte:
+		if len(val) > 0xffffffff {
+			return fmt.Errorf("bytes too long for MsgPack: %d bytes", len(val))
+		}
+		buf.WriteByte(0xc6)
+		binary.Write(buf, binary.BigEndian, uint32(len(val)))
+		buf.Write(val)
+	case time.Time:
+		// MsgPack's native timestamp ext type needs its own registered
+		// extension ID to decode; an RFC3339Nano string round-trips
+		// through any MsgPack reader without one.
+		return msgpackWrite(buf, val.UTC().Format(time.RFC3339Nano))
+	case []interface{}:
+		buf.WriteByte(0xdd)
+		binary.Write(buf, binary.BigEndian, uint32(len(val)))
+		for _, elem := range val {
+			if err := msgpackWrite(buf, elem); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(len(val)))
+		for key, elem := range val {
+			if err := msgpackWrite(buf, key); err != nil {
+				return err
+			}
+			if err := msgpackWrite(buf, elem); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value type for MsgPack: %T", v)
+	}
+	return nil
+}
@@ -19,68 +19,128 @@ func NewParser(tokens []Token) *Parser {
 	}
 }
 
+// ParseError is a single syntax error found while parsing a query,
+// located by the line/column of the token that triggered it.
+type ParseError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// ParseErrors collects every syntax error query() recovered from, rather
+// than just the first, so a CLI can report all of them in one pass. It's
+// what Parse returns whenever parsing fails.
+type ParseErrors []ParseError
+
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, pe := range e {
+		msgs[i] = pe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Errors exposes the individual errors collected, for callers that want
+// to render each one separately (e.g. a CLI pointing at every line/column
+// in turn) instead of the single joined Error() string.
+func (e ParseErrors) Errors() []ParseError {
+	return e
+}
+
 // Parse parses the query into an AST
 func (p *Parser) Parse() (ASTNode, error) {
 	if p.pos >= len(p.tokens) {
 		return ASTNode{}, fmt.Errorf("empty query")
 	}
-	node, err := p.query()
-	if err != nil {
-		return ASTNode{}, err
-	}
-	if p.pos < len(p.tokens) && p.tokens[p.pos].Type != TokenEOF {
-		return ASTNode{}, fmt.Errorf("unexpected tokens at position %d", p.pos)
+	return p.query()
+}
+
+// synchronizingTokens are the tokens recover() treats as a safe place to
+// resume parsing after a syntax error: each clause keyword starts a new
+// top-level clause, and ';' ends a statement, so neither can be part of
+// whatever already went wrong.
+var synchronizingTokens = map[string]bool{
+	"CREATE": true, "MATCH": true, "WHERE": true,
+	"SET": true, "DELETE": true, "DETACH": true, "RETURN": true,
+}
+
+// recover implements panic-mode error recovery: it advances p.pos past
+// the tokens that caused (or followed) a syntax error, stopping at the
+// next synchronizing token, so query() can keep collecting errors from
+// the rest of the script instead of giving up after the first one.
+func (p *Parser) recover() {
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		if tok.Type == TokenEOF {
+			return
+		}
+		if tok.Type == TokenSymbol && tok.Value == ";" {
+			p.pos++
+			return
+		}
+		if tok.Type == TokenKeyword && synchronizingTokens[strings.ToUpper(tok.Value)] {
+			return
+		}
+		p.pos++
 	}
-	return node, nil
 }
 
-// query parses a full query
+// errorAt wraps err as a ParseError located at the parser's current
+// token. The clause parsers producing err generally return without
+// consuming the offending token, so p.pos still points at it.
+func (p *Parser) errorAt(err error) ParseError {
+	idx := p.pos
+	if idx >= len(p.tokens) {
+		idx = len(p.tokens) - 1
+	}
+	tok := p.tokens[idx]
+	return ParseError{Line: tok.Line, Col: tok.Col, Msg: err.Error()}
+}
+
+// query parses a full query, collecting a ParseError for each clause that
+// fails to parse and recovering to the next clause keyword or ';' rather
+// than stopping at the first one.
 func (p *Parser) query() (ASTNode, error) {
 	node := ASTNode{Type: NodeQuery}
+	var errs ParseErrors
 	for p.pos < len(p.tokens) && p.tokens[p.pos].Type != TokenEOF {
-		if p.pos >= len(p.tokens) {
-			break
-		}
-		switch strings.ToUpper(p.tokens[p.pos].Value) {
+		tok := p.tokens[p.pos]
+		var child ASTNode
+		var err error
+		switch strings.ToUpper(tok.Value) {
 		case "CREATE":
-			createNode, err := p.createClause()
-			if err != nil {
-				return ASTNode{}, err
-			}
-			node.Children = append(node.Children, createNode)
+			child, err = p.createClause()
 		case "MATCH":
-			matchNode, err := p.matchClause()
-			if err != nil {
-				return ASTNode{}, err
-			}
-			node.Children = append(node.Children, matchNode)
+			child, err = p.matchClause()
 		case "WHERE":
-			whereNode, err := p.whereClause()
-			if err != nil {
-				return ASTNode{}, err
-			}
-			node.Children = append(node.Children, whereNode)
+			child, err = p.whereClause()
 		case "SET":
-			setNode, err := p.setClause()
-			if err != nil {
-				return ASTNode{}, err
-			}
-			node.Children = append(node.Children, setNode)
+			child, err = p.setClause()
 		case "DELETE":
-			deleteNode, err := p.deleteClause()
-			if err != nil {
-				return ASTNode{}, err
-			}
-			node.Children = append(node.Children, deleteNode)
+			child, err = p.deleteClause()
+		case "DETACH":
+			child, err = p.detachDeleteClause()
 		case "RETURN":
-			returnNode, err := p.returnClause()
-			if err != nil {
-				return ASTNode{}, err
-			}
-			node.Children = append(node.Children, returnNode)
+			child, err = p.returnClause()
+		case ";":
+			p.pos++
+			continue
 		default:
-			return ASTNode{}, fmt.Errorf("unexpected token %s at position %d", p.tokens[p.pos].Value, p.pos)
+			err = fmt.Errorf("unexpected token %q", tok.Value)
+		}
+		if err != nil {
+			errs = append(errs, p.errorAt(err))
+			p.recover()
+			continue
 		}
+		node.Children = append(node.Children, child)
+	}
+	if len(errs) > 0 {
+		return ASTNode{}, errs
 	}
 	return node, nil
 }
@@ -129,7 +189,7 @@ func (p *Parser) whereClause() (ASTNode, error) {
 		return ASTNode{}, fmt.Errorf("expected WHERE at position %d", p.pos)
 	}
 	node := ASTNode{Type: NodeWhere}
-	expr, err := p.expression()
+	expr, err := p.orExpr()
 	if err != nil {
 		return ASTNode{}, err
 	}
@@ -183,6 +243,21 @@ func (p *Parser) deleteClause() (ASTNode, error) {
 	return node, nil
 }
 
+// detachDeleteClause parses a DETACH DELETE clause, which deletes each
+// named node's incident edges (and, per their cascade flags, any nodes
+// those edges pull in along with them) before deleting the node itself.
+func (p *Parser) detachDeleteClause() (ASTNode, error) {
+	if !p.expect(TokenKeyword, "DETACH") {
+		return ASTNode{}, fmt.Errorf("expected DETACH at position %d", p.pos)
+	}
+	node, err := p.deleteClause()
+	if err != nil {
+		return ASTNode{}, err
+	}
+	node.Value = "DETACH"
+	return node, nil
+}
+
 // returnClause parses a RETURN clause
 func (p *Parser) returnClause() (ASTNode, error) {
 	if !p.expect(TokenKeyword, "RETURN") {
@@ -207,9 +282,15 @@ func (p *Parser) returnClause() (ASTNode, error) {
 	return node, nil
 }
 
-// pattern parses a node or relationship pattern
+// pattern parses a node or relationship pattern, optionally bound to a
+// path variable (e.g. `p = (a)-[:FRIEND*1..3]->(b)`), in which case the
+// path variable name is stored as the NodePattern's Value.
 func (p *Parser) pattern() (ASTNode, error) {
 	node := ASTNode{Type: NodePattern}
+	if p.pos+1 < len(p.tokens) && p.tokens[p.pos].Type == TokenIdentifier && p.tokens[p.pos+1].Value == "=" {
+		node.Value = p.tokens[p.pos].Value
+		p.pos += 2
+	}
 	if p.accept(TokenSymbol, "(") {
 		// Single node pattern
 		nodeNode, err := p.node()
@@ -224,13 +305,41 @@ func (p *Parser) pattern() (ASTNode, error) {
 		return ASTNode{}, fmt.Errorf("expected ( at position %d", p.pos)
 	}
 
-	// Check for relationship pattern
-	if p.accept(TokenSymbol, "-") {
+	// Check for a relationship pattern, in any of its directional forms:
+	//   -[r:TYPE]->   forward (left node is the edge's source)
+	//   <-[r:TYPE]-   reverse (left node is the edge's target)
+	//   -[r:TYPE]-    either direction
+	var direction string
+	switch {
+	case p.accept(TokenSymbol, "<-"):
+		direction = DirectionReverse
+	case p.accept(TokenSymbol, "-"):
+		direction = DirectionForward // confirmed or downgraded to "either" below
+	}
+
+	if direction != "" {
 		rel, err := p.relationship()
 		if err != nil {
 			return ASTNode{}, err
 		}
+
+		switch direction {
+		case DirectionReverse:
+			if !p.expect(TokenSymbol, "-") {
+				return ASTNode{}, fmt.Errorf("expected - after reverse relationship at position %d", p.pos)
+			}
+		case DirectionForward:
+			if p.accept(TokenSymbol, "->") {
+				// direction stays forward
+			} else if p.expect(TokenSymbol, "-") {
+				direction = DirectionEither
+			} else {
+				return ASTNode{}, fmt.Errorf("expected -> or - at position %d", p.pos)
+			}
+		}
+		rel.Children = append(rel.Children, ASTNode{Type: NodeDirection, Value: direction})
 		node.Children = append(node.Children, rel)
+
 		if !p.expect(TokenSymbol, "(") {
 			return ASTNode{}, fmt.Errorf("expected ( after relationship at position %d", p.pos)
 		}
@@ -297,6 +406,23 @@ func (p *Parser) relationship() (ASTNode, error) {
 			Value: p.tokens[p.pos-1].Value,
 		})
 	}
+	if p.accept(TokenSymbol, "@") {
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].Type != TokenNumber {
+			return ASTNode{}, fmt.Errorf("expected ordinal after @ at position %d", p.pos)
+		}
+		node.Children = append(node.Children, ASTNode{
+			Type:  NodeOrdinal,
+			Value: p.tokens[p.pos].Value,
+		})
+		p.pos++
+	}
+	if p.accept(TokenSymbol, "*") {
+		varLen, err := p.varLength()
+		if err != nil {
+			return ASTNode{}, err
+		}
+		node.Children = append(node.Children, varLen)
+	}
 	if p.accept(TokenSymbol, "{") {
 		for p.pos < len(p.tokens) && p.tokens[p.pos].Value != "}" {
 			prop, err := p.property()
@@ -315,12 +441,38 @@ func (p *Parser) relationship() (ASTNode, error) {
 	if !p.expect(TokenSymbol, "]") {
 		return ASTNode{}, fmt.Errorf("expected ] at position %d", p.pos)
 	}
-	if !p.expect(TokenSymbol, "->") {
-		return ASTNode{}, fmt.Errorf("expected -> at position %d", p.pos)
-	}
 	return node, nil
 }
 
+// varLength parses the hop-count bound of a variable-length relationship
+// pattern, the part after `*`: `*N` (exactly N hops), `*N..M`, `*..M`
+// (min defaults to 1), `*N..` (unbounded max, capped by the executor's
+// expansion budget), or bare `*` (min 1, executor-default max). The
+// parsed bounds are carried as two NodeLiteral children, each holding an
+// int string or "" when omitted.
+func (p *Parser) varLength() (ASTNode, error) {
+	var minStr, maxStr string
+	if p.tokens[p.pos].Type == TokenNumber {
+		minStr = p.tokens[p.pos].Value
+		p.pos++
+	}
+	if p.accept(TokenSymbol, "..") {
+		if p.tokens[p.pos].Type == TokenNumber {
+			maxStr = p.tokens[p.pos].Value
+			p.pos++
+		}
+	} else if minStr != "" {
+		maxStr = minStr
+	}
+	return ASTNode{
+		Type: NodeVarLength,
+		Children: []ASTNode{
+			{Type: NodeLiteral, Value: minStr},
+			{Type: NodeLiteral, Value: maxStr},
+		},
+	}, nil
+}
+
 // property parses a property key-value pair
 func (p *Parser) property() (ASTNode, error) {
 	if !p.expect(TokenIdentifier, "") {
@@ -330,6 +482,19 @@ func (p *Parser) property() (ASTNode, error) {
 	if !p.expect(TokenSymbol, ":") {
 		return ASTNode{}, fmt.Errorf("expected : after property key at position %d", p.pos)
 	}
+	if p.pos < len(p.tokens) && p.tokens[p.pos].Type == TokenSymbol && p.tokens[p.pos].Value == "[" {
+		list, err := p.listLiteral()
+		if err != nil {
+			return ASTNode{}, err
+		}
+		return ASTNode{
+			Type: NodeProperty,
+			Children: []ASTNode{
+				{Type: NodeIdentifier, Value: key},
+				list,
+			},
+		}, nil
+	}
 	var value string
 	var propType PropertyType
 	switch p.tokens[p.pos].Type {
@@ -340,6 +505,9 @@ func (p *Parser) property() (ASTNode, error) {
 	case TokenNumber:
 		value = p.tokens[p.pos].Value
 		propType = PropertyInt
+		if strings.Contains(value, ".") {
+			propType = PropertyFloat
+		}
 		p.pos++
 	case TokenIdentifier:
 		if strings.ToLower(p.tokens[p.pos].Value) == "true" || strings.ToLower(p.tokens[p.pos].Value) == "false" {
@@ -377,6 +545,20 @@ func (p *Parser) propertyAssignment() (ASTNode, error) {
 	if !p.expect(TokenSymbol, "=") {
 		return ASTNode{}, fmt.Errorf("expected = at position %d", p.pos)
 	}
+
+	if incr, ok, err := p.selfIncrementAssignment(varNode, key); err != nil {
+		return ASTNode{}, err
+	} else if ok {
+		return ASTNode{
+			Type: NodeProperty,
+			Children: []ASTNode{
+				{Type: NodeIdentifier, Value: varNode},
+				{Type: NodeIdentifier, Value: key},
+				incr,
+			},
+		}, nil
+	}
+
 	var value string
 	var propType PropertyType
 	switch p.tokens[p.pos].Type {
@@ -387,6 +569,9 @@ func (p *Parser) propertyAssignment() (ASTNode, error) {
 	case TokenNumber:
 		value = p.tokens[p.pos].Value
 		propType = PropertyInt
+		if strings.Contains(value, ".") {
+			propType = PropertyFloat
+		}
 		p.pos++
 	case TokenIdentifier:
 		if strings.ToLower(p.tokens[p.pos].Value) == "true" || strings.ToLower(p.tokens[p.pos].Value) == "false" {
@@ -409,12 +594,246 @@ func (p *Parser) propertyAssignment() (ASTNode, error) {
 	}, nil
 }
 
-// expression parses a WHERE expression (e.g., n.key = value)
-func (p *Parser) expression() (ASTNode, error) {
+// selfIncrementAssignment recognizes a SET RHS of the exact form
+// `<var>.<key> + <number>`, where <var>.<key> repeats propertyAssignment's
+// own LHS -- e.g. `SET e.weight = e.weight + 1.5` for
+// GraphManager.IncrementEdgeWeight/IncrementNodeWeight's Cypher entry
+// point. It's the one arithmetic RHS this parser supports; general
+// expressions and $-bound query parameters on the right of SET aren't
+// implemented. Returns ok=false (no error, no tokens consumed) if the
+// upcoming tokens don't start with `<var>.<key> +`, so propertyAssignment
+// falls through to its ordinary literal-value parsing; once they do,
+// anything other than a number after the `+` is an error.
+func (p *Parser) selfIncrementAssignment(varNode, key string) (ASTNode, bool, error) {
+	if p.pos+4 >= len(p.tokens) {
+		return ASTNode{}, false, nil
+	}
+	if p.tokens[p.pos].Type != TokenIdentifier || p.tokens[p.pos].Value != varNode {
+		return ASTNode{}, false, nil
+	}
+	if p.tokens[p.pos+1].Type != TokenSymbol || p.tokens[p.pos+1].Value != "." {
+		return ASTNode{}, false, nil
+	}
+	if p.tokens[p.pos+2].Type != TokenIdentifier || p.tokens[p.pos+2].Value != key {
+		return ASTNode{}, false, nil
+	}
+	if p.tokens[p.pos+3].Type != TokenSymbol || p.tokens[p.pos+3].Value != "+" {
+		return ASTNode{}, false, nil
+	}
+	if p.tokens[p.pos+4].Type != TokenNumber {
+		return ASTNode{}, false, fmt.Errorf("expected a number after + at position %d", p.pos+4)
+	}
+	delta := p.tokens[p.pos+4].Value
+	p.pos += 5
+	return ASTNode{Type: NodeBinaryOp, Value: OpAdd, Children: []ASTNode{
+		{Type: NodeLiteral, Value: delta},
+	}}, true, nil
+}
+
+// Left-binding powers for WHERE's binary operators, lowest first. OR and
+// AND are the only operators that actually chain (`a OR b OR c`), so
+// they're the only ones parseExpr's precedence-climbing loop needs to
+// compare against minBP; comparisons and string predicates are leaf
+// terms built in one shot by comparisonExpr and just need *a* value above
+// bpAnd so a bare comparison (no OR/AND around it) still parses as the
+// right-hand side of one.
+const (
+	bpOr  = 1
+	bpAnd = 2
+)
+
+// orExpr is WHERE's entry point: precedence-climbing over AND/OR.
+func (p *Parser) orExpr() (ASTNode, error) {
+	return p.parseExpr(bpOr)
+}
+
+// parseExpr implements precedence climbing (a.k.a. a Pratt parser) over
+// AND/OR: it loops consuming operators whose left-binding power is >=
+// minBP, each time recursing into the right-hand side with bp+1 (AND/OR
+// are left-associative, so there's no same-bp recursion case the way a
+// right-associative operator would need).
+func (p *Parser) parseExpr(minBP int) (ASTNode, error) {
+	left, err := p.unaryExpr()
+	if err != nil {
+		return ASTNode{}, err
+	}
+	for {
+		var op string
+		var bp int
+		switch {
+		case p.tokens[p.pos].Type == TokenKeyword && strings.ToUpper(p.tokens[p.pos].Value) == "OR":
+			op, bp = OpOr, bpOr
+		case p.tokens[p.pos].Type == TokenKeyword && strings.ToUpper(p.tokens[p.pos].Value) == "AND":
+			op, bp = OpAnd, bpAnd
+		default:
+			return left, nil
+		}
+		if bp < minBP {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseExpr(bp + 1)
+		if err != nil {
+			return ASTNode{}, err
+		}
+		left = ASTNode{Type: NodeBinaryOp, Value: op, Children: []ASTNode{left, right}}
+	}
+}
+
+// unaryExpr parses an optional leading NOT, binding tighter than AND/OR.
+func (p *Parser) unaryExpr() (ASTNode, error) {
+	if p.accept(TokenKeyword, "NOT") {
+		operand, err := p.unaryExpr()
+		if err != nil {
+			return ASTNode{}, err
+		}
+		return ASTNode{Type: NodeUnaryOp, Value: OpNot, Children: []ASTNode{operand}}, nil
+	}
+	return p.primaryExpr()
+}
+
+// primaryExpr parses a parenthesized sub-expression, a bare list literal,
+// or a single comparison.
+func (p *Parser) primaryExpr() (ASTNode, error) {
+	if p.accept(TokenSymbol, "(") {
+		expr, err := p.parseExpr(bpOr)
+		if err != nil {
+			return ASTNode{}, err
+		}
+		if !p.expect(TokenSymbol, ")") {
+			return ASTNode{}, fmt.Errorf("expected ) at position %d", p.pos)
+		}
+		return expr, nil
+	}
+	if p.pos < len(p.tokens) && p.tokens[p.pos].Type == TokenSymbol && p.tokens[p.pos].Value == "[" {
+		return p.listLiteral()
+	}
+	if p.accept(TokenKeyword, "FULLTEXT") {
+		return p.fullTextExpr()
+	}
+	return p.comparisonExpr()
+}
+
+// fullTextExpr parses `FULLTEXT(var.key, "query")`, consumed right after
+// the FULLTEXT keyword itself. See OpFullText.
+func (p *Parser) fullTextExpr() (ASTNode, error) {
+	if !p.expect(TokenSymbol, "(") {
+		return ASTNode{}, fmt.Errorf("expected ( after FULLTEXT at position %d", p.pos)
+	}
+	propRef, err := p.propertyAccess()
+	if err != nil {
+		return ASTNode{}, err
+	}
+	if !p.expect(TokenSymbol, ",") {
+		return ASTNode{}, fmt.Errorf("expected , at position %d", p.pos)
+	}
+	lit, err := p.literal()
+	if err != nil {
+		return ASTNode{}, err
+	}
+	if len(lit.Children) != 1 || lit.Children[0].Value != "string" {
+		return ASTNode{}, fmt.Errorf("FULLTEXT requires a string query at position %d", p.pos)
+	}
+	if !p.expect(TokenSymbol, ")") {
+		return ASTNode{}, fmt.Errorf("expected ) at position %d", p.pos)
+	}
+	return ASTNode{Type: NodeBinaryOp, Value: OpFullText, Children: []ASTNode{propRef, lit}}, nil
+}
+
+// comparisonExpr parses a single `var.key OP ...` comparison: `=`, `<>`,
+// `<`, `<=`, `>`, `>=`, `=~` (regex match), `IN [...]`, `IS [NOT] NULL`,
+// or the string predicates `STARTS WITH`/`ENDS WITH`/`CONTAINS`. These
+// operators don't chain, so they're parsed directly rather than through
+// parseExpr's loop.
+func (p *Parser) comparisonExpr() (ASTNode, error) {
+	propRef, err := p.propertyAccess()
+	if err != nil {
+		return ASTNode{}, err
+	}
+
+	if p.accept(TokenKeyword, "IS") {
+		op := OpIsNull
+		if p.accept(TokenKeyword, "NOT") {
+			op = OpIsNotNull
+		}
+		if !p.expect(TokenKeyword, "NULL") {
+			return ASTNode{}, fmt.Errorf("expected NULL at position %d", p.pos)
+		}
+		return ASTNode{Type: NodeUnaryOp, Value: op, Children: []ASTNode{propRef}}, nil
+	}
+
+	if p.accept(TokenKeyword, "IN") {
+		list, err := p.listLiteral()
+		if err != nil {
+			return ASTNode{}, err
+		}
+		return ASTNode{Type: NodeBinaryOp, Value: OpIn, Children: []ASTNode{propRef, list}}, nil
+	}
+
+	if p.accept(TokenKeyword, "STARTS") {
+		if !p.expect(TokenKeyword, "WITH") {
+			return ASTNode{}, fmt.Errorf("expected WITH after STARTS at position %d", p.pos)
+		}
+		lit, err := p.literal()
+		if err != nil {
+			return ASTNode{}, err
+		}
+		return ASTNode{Type: NodeBinaryOp, Value: OpStartsWith, Children: []ASTNode{propRef, lit}}, nil
+	}
+	if p.accept(TokenKeyword, "ENDS") {
+		if !p.expect(TokenKeyword, "WITH") {
+			return ASTNode{}, fmt.Errorf("expected WITH after ENDS at position %d", p.pos)
+		}
+		lit, err := p.literal()
+		if err != nil {
+			return ASTNode{}, err
+		}
+		return ASTNode{Type: NodeBinaryOp, Value: OpEndsWith, Children: []ASTNode{propRef, lit}}, nil
+	}
+	if p.accept(TokenKeyword, "CONTAINS") {
+		lit, err := p.literal()
+		if err != nil {
+			return ASTNode{}, err
+		}
+		return ASTNode{Type: NodeBinaryOp, Value: OpContains, Children: []ASTNode{propRef, lit}}, nil
+	}
+
+	var op string
+	switch {
+	case p.accept(TokenSymbol, "=~"):
+		op = OpRegexMatch
+	case p.accept(TokenSymbol, "<>"):
+		op = OpNotEqual
+	case p.accept(TokenSymbol, "<="):
+		op = OpLessEqual
+	case p.accept(TokenSymbol, ">="):
+		op = OpGreaterEqual
+	case p.accept(TokenSymbol, "<"):
+		op = OpLess
+	case p.accept(TokenSymbol, ">"):
+		op = OpGreater
+	case p.accept(TokenSymbol, "="):
+		op = OpEqual
+	default:
+		return ASTNode{}, fmt.Errorf("expected comparison operator at position %d", p.pos)
+	}
+
+	lit, err := p.literal()
+	if err != nil {
+		return ASTNode{}, err
+	}
+	return ASTNode{Type: NodeBinaryOp, Value: op, Children: []ASTNode{propRef, lit}}, nil
+}
+
+// propertyAccess parses `var.key`, the only primary form comparisons and
+// string predicates operate on. It's represented the same way a
+// NodeProperty's key is: an identifier node carrying the property key as
+// its single child.
+func (p *Parser) propertyAccess() (ASTNode, error) {
 	if !p.expect(TokenIdentifier, "") {
 		return ASTNode{}, fmt.Errorf("expected identifier at position %d", p.pos)
 	}
-	varNode := p.tokens[p.pos-1].Value
+	varName := p.tokens[p.pos-1].Value
 	if !p.expect(TokenSymbol, ".") {
 		return ASTNode{}, fmt.Errorf("expected . at position %d", p.pos)
 	}
@@ -422,9 +841,41 @@ func (p *Parser) expression() (ASTNode, error) {
 		return ASTNode{}, fmt.Errorf("expected property key at position %d", p.pos)
 	}
 	key := p.tokens[p.pos-1].Value
-	if !p.expect(TokenSymbol, "=") {
-		return ASTNode{}, fmt.Errorf("expected = at position %d", p.pos)
+	return ASTNode{
+		Type:     NodeIdentifier,
+		Value:    varName,
+		Children: []ASTNode{{Type: NodeIdentifier, Value: key}},
+	}, nil
+}
+
+// listLiteral parses a bracketed, comma-separated list of literals, used
+// both as IN's right-hand side and as a standalone primary expression.
+func (p *Parser) listLiteral() (ASTNode, error) {
+	if !p.expect(TokenSymbol, "[") {
+		return ASTNode{}, fmt.Errorf("expected [ at position %d", p.pos)
+	}
+	node := ASTNode{Type: NodeList}
+	if !(p.pos < len(p.tokens) && p.tokens[p.pos].Type == TokenSymbol && p.tokens[p.pos].Value == "]") {
+		for {
+			lit, err := p.literal()
+			if err != nil {
+				return ASTNode{}, err
+			}
+			node.Children = append(node.Children, lit)
+			if !p.accept(TokenSymbol, ",") {
+				break
+			}
+		}
+	}
+	if !p.expect(TokenSymbol, "]") {
+		return ASTNode{}, fmt.Errorf("expected ] at position %d", p.pos)
 	}
+	return node, nil
+}
+
+// literal parses a single string, number (int or float), or boolean
+// literal into a NodeLiteral tagged with its PropertyType.
+func (p *Parser) literal() (ASTNode, error) {
 	var value string
 	var propType PropertyType
 	switch p.tokens[p.pos].Type {
@@ -435,6 +886,9 @@ func (p *Parser) expression() (ASTNode, error) {
 	case TokenNumber:
 		value = p.tokens[p.pos].Value
 		propType = PropertyInt
+		if strings.Contains(value, ".") {
+			propType = PropertyFloat
+		}
 		p.pos++
 	case TokenIdentifier:
 		if strings.ToLower(p.tokens[p.pos].Value) == "true" || strings.ToLower(p.tokens[p.pos].Value) == "false" {
@@ -442,19 +896,12 @@ func (p *Parser) expression() (ASTNode, error) {
 			propType = PropertyBool
 			p.pos++
 		} else {
-			return ASTNode{}, fmt.Errorf("invalid expression value at position %d", p.pos)
+			return ASTNode{}, fmt.Errorf("invalid literal value at position %d", p.pos)
 		}
 	default:
-		return ASTNode{}, fmt.Errorf("expected expression value at position %d", p.pos)
+		return ASTNode{}, fmt.Errorf("expected literal value at position %d", p.pos)
 	}
-	return ASTNode{
-		Type: NodeExpression,
-		Children: []ASTNode{
-			{Type: NodeIdentifier, Value: varNode},
-			{Type: NodeIdentifier, Value: key},
-			{Type: NodeLiteral, Value: value, Children: []ASTNode{{Type: NodeLiteral, Value: propType.String()}}},
-		},
-	}, nil
+	return ASTNode{Type: NodeLiteral, Value: value, Children: []ASTNode{{Type: NodeLiteral, Value: propType.String()}}}, nil
 }
 
 // expect checks and consumes a token
@@ -501,6 +948,18 @@ func (pt PropertyType) String() string {
 		return "string"
 	case PropertyBool:
 		return "bool"
+	case PropertyFloat:
+		return "float"
+	case PropertyTimestamp:
+		return "timestamp"
+	case PropertyBytes:
+		return "bytes"
+	case PropertyList:
+		return "list"
+	case PropertyMap:
+		return "map"
+	case PropertyNull:
+		return "null"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,308 @@
+package graphdb
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Predicate is a reusable boolean filter over a Node or Edge. WHERE
+// compiles its AST into a Predicate once per query (regexes included),
+// so the same tree can be applied by post-match filtering today and,
+// later, pushed down into index scans without re-parsing anything.
+type Predicate interface {
+	MatchNode(n Node) bool
+	MatchEdge(e Edge) bool
+}
+
+// comparisonPredicate is a single `key OP value` leaf predicate.
+type comparisonPredicate struct {
+	key    string
+	op     string
+	value  interface{}
+	values []interface{}
+	re     *regexp.Regexp
+}
+
+func (c comparisonPredicate) MatchNode(n Node) bool { return c.match(n.Properties) }
+func (c comparisonPredicate) MatchEdge(e Edge) bool { return c.match(e.Properties) }
+
+func (c comparisonPredicate) match(props []Property) bool {
+	pv, exists := propertyValue(props, c.key)
+	switch c.op {
+	case OpIsNull:
+		return !exists
+	case OpIsNotNull:
+		return exists
+	}
+	if !exists {
+		return false
+	}
+	switch c.op {
+	case OpEqual:
+		return pv == c.value
+	case OpNotEqual:
+		return pv != c.value
+	case OpRegexMatch:
+		s, ok := pv.(string)
+		return ok && c.re.MatchString(s)
+	case OpIn:
+		for _, v := range c.values {
+			if pv == v {
+				return true
+			}
+		}
+		return false
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+		return compareOrdered(pv, c.value, c.op)
+	case OpStartsWith:
+		s, ok := pv.(string)
+		target, tok := c.value.(string)
+		return ok && tok && strings.HasPrefix(s, target)
+	case OpEndsWith:
+		s, ok := pv.(string)
+		target, tok := c.value.(string)
+		return ok && tok && strings.HasSuffix(s, target)
+	case OpContains:
+		s, ok := pv.(string)
+		target, tok := c.value.(string)
+		return ok && tok && strings.Contains(s, target)
+	case OpFullText:
+		s, ok := pv.(string)
+		query, qok := c.value.(string)
+		return ok && qok && matchesFullText(s, query)
+	default:
+		return false
+	}
+}
+
+// andPredicate, orPredicate and notPredicate combine sub-predicates,
+// short-circuiting via Go's own &&/||/! operators.
+type andPredicate struct{ left, right Predicate }
+
+func (p andPredicate) MatchNode(n Node) bool { return p.left.MatchNode(n) && p.right.MatchNode(n) }
+func (p andPredicate) MatchEdge(e Edge) bool { return p.left.MatchEdge(e) && p.right.MatchEdge(e) }
+
+type orPredicate struct{ left, right Predicate }
+
+func (p orPredicate) MatchNode(n Node) bool { return p.left.MatchNode(n) || p.right.MatchNode(n) }
+func (p orPredicate) MatchEdge(e Edge) bool { return p.left.MatchEdge(e) || p.right.MatchEdge(e) }
+
+type notPredicate struct{ inner Predicate }
+
+func (p notPredicate) MatchNode(n Node) bool { return !p.inner.MatchNode(n) }
+func (p notPredicate) MatchEdge(e Edge) bool { return !p.inner.MatchEdge(e) }
+
+// buildPredicate compiles a WHERE AST (NodeBinaryOp / NodeUnaryOp, as
+// produced by Parser.orExpr) into a Predicate. It also returns the single
+// variable name the predicate applies to -- WHERE only ever filters one
+// bound variable's slice, so a clause that mixes variables is rejected
+// rather than silently misapplied.
+func buildPredicate(node ASTNode) (varName string, pred Predicate, err error) {
+	switch node.Type {
+	case NodeBinaryOp:
+		switch node.Value {
+		case OpAnd, OpOr:
+			leftVar, left, err := buildPredicate(node.Children[0])
+			if err != nil {
+				return "", nil, err
+			}
+			rightVar, right, err := buildPredicate(node.Children[1])
+			if err != nil {
+				return "", nil, err
+			}
+			if leftVar != rightVar {
+				return "", nil, fmt.Errorf("WHERE clause cannot combine conditions on different variables (%s and %s)", leftVar, rightVar)
+			}
+			if node.Value == OpAnd {
+				return leftVar, andPredicate{left, right}, nil
+			}
+			return leftVar, orPredicate{left, right}, nil
+		default:
+			return buildComparison(node)
+		}
+	case NodeUnaryOp:
+		switch node.Value {
+		case OpNot:
+			v, inner, err := buildPredicate(node.Children[0])
+			if err != nil {
+				return "", nil, err
+			}
+			return v, notPredicate{inner}, nil
+		case OpIsNull, OpIsNotNull:
+			return buildComparison(node)
+		default:
+			return "", nil, fmt.Errorf("invalid WHERE expression")
+		}
+	default:
+		return "", nil, fmt.Errorf("invalid WHERE expression")
+	}
+}
+
+// buildComparison compiles a single comparison/IN/IS NULL/string-predicate
+// leaf (a NodeBinaryOp or NodeUnaryOp whose first child is a propertyAccess
+// node) into a comparisonPredicate.
+func buildComparison(node ASTNode) (string, Predicate, error) {
+	if len(node.Children) < 1 {
+		return "", nil, fmt.Errorf("invalid expression format")
+	}
+	propRef := node.Children[0]
+	if len(propRef.Children) != 1 {
+		return "", nil, fmt.Errorf("invalid property reference")
+	}
+	varName := propRef.Value
+	key := propRef.Children[0].Value
+	op := node.Value
+
+	switch op {
+	case OpIsNull, OpIsNotNull:
+		return varName, comparisonPredicate{key: key, op: op}, nil
+
+	case OpIn:
+		if len(node.Children) != 2 || node.Children[1].Type != NodeList {
+			return "", nil, fmt.Errorf("invalid expression format")
+		}
+		values := make([]interface{}, 0, len(node.Children[1].Children))
+		for _, lit := range node.Children[1].Children {
+			v, err := literalValue(lit)
+			if err != nil {
+				return "", nil, err
+			}
+			values = append(values, v)
+		}
+		return varName, comparisonPredicate{key: key, op: op, values: values}, nil
+
+	case OpRegexMatch:
+		if len(node.Children) != 2 {
+			return "", nil, fmt.Errorf("invalid expression format")
+		}
+		lit := node.Children[1]
+		if len(lit.Children) != 1 || lit.Children[0].Value != "string" {
+			return "", nil, fmt.Errorf("=~ requires a string pattern")
+		}
+		re, err := regexp.Compile(lit.Value)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid regex pattern: %v", err)
+		}
+		return varName, comparisonPredicate{key: key, op: op, re: re}, nil
+
+	case OpStartsWith, OpEndsWith, OpContains, OpFullText:
+		if len(node.Children) != 2 {
+			return "", nil, fmt.Errorf("invalid expression format")
+		}
+		lit := node.Children[1]
+		if len(lit.Children) != 1 || lit.Children[0].Value != "string" {
+			return "", nil, fmt.Errorf("%s requires a string argument", op)
+		}
+		return varName, comparisonPredicate{key: key, op: op, value: lit.Value}, nil
+
+	default:
+		if len(node.Children) != 2 {
+			return "", nil, fmt.Errorf("invalid expression format")
+		}
+		v, err := literalValue(node.Children[1])
+		if err != nil {
+			return "", nil, err
+		}
+		if op == OpLess || op == OpLessEqual || op == OpGreater || op == OpGreaterEqual {
+			if _, ok := v.(bool); ok {
+				return "", nil, fmt.Errorf("operator %s is not supported for bool properties", op)
+			}
+		}
+		return varName, comparisonPredicate{key: key, op: op, value: v}, nil
+	}
+}
+
+// literalValue converts a NodeLiteral (tagged with its PropertyType) into
+// a typed Go value, matching the types Property.Value holds.
+func literalValue(lit ASTNode) (interface{}, error) {
+	if len(lit.Children) != 1 {
+		return nil, fmt.Errorf("invalid literal value")
+	}
+	switch lit.Children[0].Value {
+	case "int":
+		v, err := strconv.ParseInt(lit.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int value: %v", err)
+		}
+		return v, nil
+	case "float":
+		v, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float value: %v", err)
+		}
+		return v, nil
+	case "string":
+		return lit.Value, nil
+	case "bool":
+		return strings.ToLower(lit.Value) == "true", nil
+	default:
+		return nil, fmt.Errorf("unsupported property type: %s", lit.Children[0].Value)
+	}
+}
+
+// propertyValue looks up key in props, returning its value and whether it
+// was found.
+func propertyValue(props []Property, key string) (interface{}, bool) {
+	for _, prop := range props {
+		if prop.Key == key {
+			return prop.Value, true
+		}
+	}
+	return nil, false
+}
+
+// compareOrdered evaluates an ordering comparison (<, <=, >, >=) between
+// two property values of the same underlying type. Values of differing or
+// unordered (bool) types never satisfy an ordering comparison.
+func compareOrdered(a, b interface{}, op string) bool {
+	switch av := a.(type) {
+	case int64:
+		bv, ok := b.(int64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case OpLess:
+			return av < bv
+		case OpLessEqual:
+			return av <= bv
+		case OpGreater:
+			return av > bv
+		case OpGreaterEqual:
+			return av >= bv
+		}
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return false
+		}
+		switch op {
+		case OpLess:
+			return av < bv
+		case OpLessEqual:
+			return av <= bv
+		case OpGreater:
+			return av > bv
+		case OpGreaterEqual:
+			return av >= bv
+		}
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case OpLess:
+			return av < bv
+		case OpLessEqual:
+			return av <= bv
+		case OpGreater:
+			return av > bv
+		case OpGreaterEqual:
+			return av >= bv
+		}
+	}
+	return false
+}
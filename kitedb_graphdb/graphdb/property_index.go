@@ -0,0 +1,193 @@
+package graphdb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IndexDef describes a secondary property index: which node label it
+// covers, which property key(s) it's keyed by (more than one makes it a
+// composite index), and whether it enforces uniqueness. It's the unit
+// CreateIndex/DropIndex operate on and the one the WAL persists so
+// RebuildIndexes can recreate it without CreateIndex being re-run by hand.
+type IndexDef struct {
+	Label  string
+	Props  []string
+	Unique bool
+}
+
+// key is the dotted "Label.propA.propB" name CreateIndex/DropIndex key
+// IndexManager.properties by.
+func (d IndexDef) key() string {
+	return d.Label + "." + strings.Join(d.Props, ".")
+}
+
+// defaultIndexFanout is the BPlusTreeIndex fan-out CreateIndex builds
+// with; see NewBPlusTreeIndex for what it trades off.
+const defaultIndexFanout = 32
+
+// propertyIndex pairs an index's definition with the tree backing it.
+type propertyIndex struct {
+	def  IndexDef
+	tree *BPlusTreeIndex
+}
+
+// CreateIndex builds a secondary index over label's propert(ies) -- a
+// single name for a plain index, several for a composite one -- backed by
+// a BPlusTreeIndex. It only registers the (empty) index; callers with a
+// *GraphManager in hand should follow up with populateIndex to backfill it
+// from nodes already carrying label (GraphDB.CreateIndex does this).
+func (im *IndexManager) CreateIndex(label string, unique bool, props ...string) error {
+	if len(props) == 0 {
+		return fmt.Errorf("CreateIndex requires at least one property")
+	}
+	def := IndexDef{Label: label, Props: props, Unique: unique}
+	if _, exists := im.properties[def.key()]; exists {
+		return fmt.Errorf("index already exists on %s(%s)", label, strings.Join(props, ", "))
+	}
+	im.properties[def.key()] = &propertyIndex{
+		def:  def,
+		tree: NewBPlusTreeIndex(defaultIndexFanout, unique),
+	}
+	return nil
+}
+
+// DropIndex removes the index CreateIndex built for label(props...).
+func (im *IndexManager) DropIndex(label string, props ...string) error {
+	key := (IndexDef{Label: label, Props: props}).key()
+	if _, exists := im.properties[key]; !exists {
+		return fmt.Errorf("no index on %s(%s)", label, strings.Join(props, ", "))
+	}
+	delete(im.properties, key)
+	return nil
+}
+
+// LookupIndex returns the index on label(props...), if CreateIndex has
+// built one.
+func (im *IndexManager) LookupIndex(label string, props ...string) (Index, bool) {
+	idx, exists := im.properties[(IndexDef{Label: label, Props: props}).key()]
+	if !exists {
+		return nil, false
+	}
+	return idx.tree, true
+}
+
+// IndexDefs returns the definition of every index currently registered,
+// for WALManager.LogIndexDefinition to persist.
+func (im *IndexManager) IndexDefs() []IndexDef {
+	defs := make([]IndexDef, 0, len(im.properties))
+	for _, idx := range im.properties {
+		defs = append(defs, idx.def)
+	}
+	return defs
+}
+
+// populateIndex scans every active node with label def.Label already in
+// graph and inserts it into the index CreateIndex just registered for
+// def. IndexManager itself doesn't hold a *GraphManager, so this is called
+// by GraphDB.CreateIndex (and RebuildIndexes) right after registering the
+// definition, rather than from CreateIndex itself.
+func (im *IndexManager) populateIndex(def IndexDef, graph *GraphManager) error {
+	idx, exists := im.properties[def.key()]
+	if !exists {
+		return fmt.Errorf("no index on %s(%s)", def.Label, strings.Join(def.Props, ", "))
+	}
+	for _, nodeID := range graph.nodeLabelMap[def.Label] {
+		node, err := graph.GetNode(nodeID)
+		if err != nil || !node.Active {
+			continue
+		}
+		key, ok := indexKey(def, node.Properties)
+		if !ok {
+			continue
+		}
+		if err := idx.tree.Insert(key, nodeID); err != nil {
+			return fmt.Errorf("failed to backfill node %d into index %s: %v", nodeID, def.key(), err)
+		}
+	}
+	return nil
+}
+
+// indexKey builds the key a node's properties index to under def,
+// returning ok=false if the node is missing one of def's properties (it
+// simply isn't represented in that index, since property schemas aren't
+// enforced across nodes sharing a label).
+func indexKey(def IndexDef, properties []Property) (interface{}, bool) {
+	if len(def.Props) == 1 {
+		return propertyValue(properties, def.Props[0])
+	}
+	key := make([]interface{}, len(def.Props))
+	for i, prop := range def.Props {
+		v, ok := propertyValue(properties, prop)
+		if !ok {
+			return nil, false
+		}
+		key[i] = v
+	}
+	return key, true
+}
+
+// checkUnique reports an error if, under any unique index covering one of
+// labels, properties' key is already held by a node other than nodeID. It
+// mutates nothing, so AddNode/UpdateNode can call it before committing a
+// record write and abort cleanly on a violation.
+func (im *IndexManager) checkUnique(labels []string, properties []Property, nodeID int64) error {
+	labelSet := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		labelSet[l] = true
+	}
+	for _, idx := range im.properties {
+		if !idx.def.Unique || !labelSet[idx.def.Label] {
+			continue
+		}
+		key, ok := indexKey(idx.def, properties)
+		if !ok {
+			continue
+		}
+		for _, id := range idx.tree.Seek(key, key) {
+			if id != nodeID {
+				return fmt.Errorf("unique index violation on %s(%s) for key %v", idx.def.Label, strings.Join(idx.def.Props, ", "), key)
+			}
+		}
+	}
+	return nil
+}
+
+// indexNode inserts nodeID into every index covering one of labels.
+func (im *IndexManager) indexNode(labels []string, properties []Property, nodeID int64) error {
+	labelSet := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		labelSet[l] = true
+	}
+	for _, idx := range im.properties {
+		if !labelSet[idx.def.Label] {
+			continue
+		}
+		key, ok := indexKey(idx.def, properties)
+		if !ok {
+			continue
+		}
+		if err := idx.tree.Insert(key, nodeID); err != nil {
+			return fmt.Errorf("index %s: %v", idx.def.key(), err)
+		}
+	}
+	return nil
+}
+
+// unindexNode removes nodeID from every index covering one of labels.
+func (im *IndexManager) unindexNode(labels []string, properties []Property, nodeID int64) {
+	labelSet := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		labelSet[l] = true
+	}
+	for _, idx := range im.properties {
+		if !labelSet[idx.def.Label] {
+			continue
+		}
+		key, ok := indexKey(idx.def, properties)
+		if !ok {
+			continue
+		}
+		_ = idx.tree.Delete(key, nodeID)
+	}
+}
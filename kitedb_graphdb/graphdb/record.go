@@ -1,60 +1,275 @@
 package graphdb
 
 import (
+	"encoding/binary"
 	"fmt"
 )
 
-// RecordManager handles page-level record operations
+// RecordID locates a single record inside a slotted page: PageID selects
+// the page (via BufferPool/StorageManager as before), SlotID indexes that
+// page's slot directory.
+type RecordID struct {
+	PageID int
+	SlotID int
+}
+
+const (
+	slottedPageHeaderSize = 8 // slotCount (4 bytes) + dataStart (4 bytes)
+	slotEntrySize         = 8 // offset (4 bytes) + length (4 bytes)
+
+	// slotCompactionThreshold is the fraction of a page still live
+	// (header + directory + non-tombstoned record bytes) below which
+	// DeleteRecord compacts it: below this, tombstoned slots are wasting
+	// enough space to justify the rewrite.
+	slotCompactionThreshold = 0.5
+)
+
+// slotEntry is one directory entry inside a slotted page. length == 0
+// marks a tombstoned (deleted or never-used) slot.
+type slotEntry struct {
+	offset int
+	length int
+}
+
+// slotDirectory is a decoded, in-memory view of one page's slotted
+// layout: a small header, a directory of slot entries growing forward
+// from the header, and record data packed backward from the end of the
+// page. Growing the two regions from opposite ends lets the directory
+// gain a new slot without having to shift existing record data.
+type slotDirectory struct {
+	dataStart int // byte offset where the record-data region currently begins
+	slots     []slotEntry
+	page      []byte
+}
+
+// newSlotDirectory builds an empty slotted page of pageSize bytes.
+func newSlotDirectory(pageSize int) *slotDirectory {
+	sd := &slotDirectory{page: make([]byte, pageSize), dataStart: pageSize}
+	sd.flushHeader()
+	return sd
+}
+
+// decodeSlotDirectory parses page as a slotted page. page is retained by
+// reference, not copied, since callers write it straight back out.
+func decodeSlotDirectory(page []byte) *slotDirectory {
+	sd := &slotDirectory{page: page}
+	slotCount := int(binary.LittleEndian.Uint32(page[0:4]))
+	sd.dataStart = int(binary.LittleEndian.Uint32(page[4:8]))
+	sd.slots = make([]slotEntry, slotCount)
+	for i := 0; i < slotCount; i++ {
+		base := slottedPageHeaderSize + i*slotEntrySize
+		sd.slots[i] = slotEntry{
+			offset: int(binary.LittleEndian.Uint32(page[base : base+4])),
+			length: int(binary.LittleEndian.Uint32(page[base+4 : base+8])),
+		}
+	}
+	return sd
+}
+
+func (sd *slotDirectory) flushHeader() {
+	binary.LittleEndian.PutUint32(sd.page[0:4], uint32(len(sd.slots)))
+	binary.LittleEndian.PutUint32(sd.page[4:8], uint32(sd.dataStart))
+}
+
+func (sd *slotDirectory) flushSlot(i int) {
+	base := slottedPageHeaderSize + i*slotEntrySize
+	binary.LittleEndian.PutUint32(sd.page[base:base+4], uint32(sd.slots[i].offset))
+	binary.LittleEndian.PutUint32(sd.page[base+4:base+8], uint32(sd.slots[i].length))
+}
+
+// freeSpace returns how many bytes remain for a new slot entry plus its
+// record data.
+func (sd *slotDirectory) freeSpace() int {
+	return sd.dataStart - (slottedPageHeaderSize + len(sd.slots)*slotEntrySize)
+}
+
+// insert appends data as a new slot and returns its SlotID.
+func (sd *slotDirectory) insert(data []byte) (int, error) {
+	if sd.freeSpace() < len(data)+slotEntrySize {
+		return -1, fmt.Errorf("page has no room for a %d-byte record", len(data))
+	}
+	sd.dataStart -= len(data)
+	copy(sd.page[sd.dataStart:sd.dataStart+len(data)], data)
+	sd.slots = append(sd.slots, slotEntry{offset: sd.dataStart, length: len(data)})
+	sd.flushHeader()
+	sd.flushSlot(len(sd.slots) - 1)
+	return len(sd.slots) - 1, nil
+}
+
+// get returns a copy of slotID's record bytes.
+func (sd *slotDirectory) get(slotID int) ([]byte, error) {
+	if slotID < 0 || slotID >= len(sd.slots) {
+		return nil, fmt.Errorf("slot %d out of range", slotID)
+	}
+	s := sd.slots[slotID]
+	if s.length == 0 {
+		return nil, fmt.Errorf("slot %d is empty or deleted", slotID)
+	}
+	out := make([]byte, s.length)
+	copy(out, sd.page[s.offset:s.offset+s.length])
+	return out, nil
+}
+
+// tombstone marks slotID deleted. Its bytes stay in the page until
+// compact reclaims them.
+func (sd *slotDirectory) tombstone(slotID int) error {
+	if slotID < 0 || slotID >= len(sd.slots) {
+		return fmt.Errorf("slot %d out of range", slotID)
+	}
+	sd.slots[slotID] = slotEntry{}
+	sd.flushSlot(slotID)
+	return nil
+}
+
+// liveFraction reports what share of the page is still occupied by the
+// header, the slot directory, and non-tombstoned record bytes -- the
+// signal RecordManager.DeleteRecord uses to decide whether a page is
+// worth compacting.
+func (sd *slotDirectory) liveFraction() float64 {
+	if len(sd.page) == 0 {
+		return 1
+	}
+	live := slottedPageHeaderSize + len(sd.slots)*slotEntrySize
+	for _, s := range sd.slots {
+		live += s.length
+	}
+	return float64(live) / float64(len(sd.page))
+}
+
+// compact rewrites the page's data region so tombstoned slots' bytes are
+// reclaimed. Every surviving slot keeps its SlotID (and hence stays
+// reachable through any RecordID already pointing at it); only the
+// backing bytes move.
+func (sd *slotDirectory) compact() {
+	rebuilt := make([]byte, len(sd.page))
+	cursor := len(rebuilt)
+	for i, s := range sd.slots {
+		if s.length == 0 {
+			continue
+		}
+		cursor -= s.length
+		copy(rebuilt[cursor:cursor+s.length], sd.page[s.offset:s.offset+s.length])
+		sd.slots[i] = slotEntry{offset: cursor, length: s.length}
+	}
+	sd.page = rebuilt
+	sd.dataStart = cursor
+	sd.flushHeader()
+	for i := range sd.slots {
+		sd.flushSlot(i)
+	}
+}
+
+// RecordManager handles slotted-page record storage: encoding records
+// through a pluggable Codec and packing several onto a single page via a
+// slot directory, rather than one record per page.
 type RecordManager struct {
 	bufferPool *BufferPool
 	pageSize   int
+	codec      Codec
+
+	// activePage is the page RecordManager last wrote to, reused for
+	// subsequent small records until it runs out of room.
+	activePage int
 }
 
-// NewRecordManager initializes a new RecordManager
+// NewRecordManager initializes a new RecordManager. New records are
+// encoded with CompactCodec unless SetCodec overrides it.
 func NewRecordManager(bufferPool *BufferPool, pageSize int) *RecordManager {
 	return &RecordManager{
 		bufferPool: bufferPool,
 		pageSize:   pageSize,
+		codec:      CompactCodec{},
+		activePage: -1,
 	}
 }
 
-// WriteRecord serializes and writes a record (Node or Edge) to a new page
-func (rm *RecordManager) WriteRecord(record interface{}) (int, error) {
-	data, err := Serialize(record)
-	if err != nil {
-		return -1, fmt.Errorf("failed to serialize record: %v", err)
-	}
+// SetCodec overrides the Codec used to encode/decode records written from
+// this point on. It does not rewrite records already on disk -- readers
+// must still use whatever codec wrote a given record.
+func (rm *RecordManager) SetCodec(codec Codec) {
+	rm.codec = codec
+}
 
-	if len(data) > rm.pageSize {
-		return -1, fmt.Errorf("record size %d exceeds page size %d", len(data), rm.pageSize)
+// pageWithRoom returns a page, allocating a fresh one if necessary, with
+// room for a record of the given encoded length, along with its decoded
+// slot directory.
+func (rm *RecordManager) pageWithRoom(need int) (int, *slotDirectory, error) {
+	if rm.activePage >= 0 {
+		if data, err := rm.bufferPool.GetPage(rm.activePage); err == nil {
+			sd := decodeSlotDirectory(data)
+			if sd.freeSpace() >= need+slotEntrySize {
+				return rm.activePage, sd, nil
+			}
+		}
 	}
-
 	pageID, err := rm.bufferPool.storage.AllocatePage()
 	if err != nil {
-		return -1, fmt.Errorf("failed to allocate page: %v", err)
+		return -1, nil, fmt.Errorf("failed to allocate page: %v", err)
 	}
+	rm.activePage = pageID
+	return pageID, newSlotDirectory(rm.pageSize), nil
+}
 
-	// Pad data to page size
-	paddedData := make([]byte, rm.pageSize)
-	copy(paddedData, data)
+// WriteRecord encodes and writes a record (Node or Edge), packing it onto
+// the active page if it has room and otherwise onto a freshly allocated
+// page (see pageWithRoom), and returns the RecordID it was stored at.
+func (rm *RecordManager) WriteRecord(record interface{}) (RecordID, error) {
+	data, err := rm.codec.Encode(record)
+	if err != nil {
+		return RecordID{}, fmt.Errorf("failed to encode record: %v", err)
+	}
+	if len(data)+slottedPageHeaderSize+slotEntrySize > rm.pageSize {
+		return RecordID{}, fmt.Errorf("record size %d exceeds page capacity %d", len(data), rm.pageSize)
+	}
 
-	if err := rm.bufferPool.WritePage(pageID, paddedData); err != nil {
-		return -1, fmt.Errorf("failed to write record to page %d: %v", pageID, err)
+	pageID, sd, err := rm.pageWithRoom(len(data))
+	if err != nil {
+		return RecordID{}, err
 	}
 
-	return pageID, nil
+	slotID, err := sd.insert(data)
+	if err != nil {
+		return RecordID{}, fmt.Errorf("failed to insert into page %d: %v", pageID, err)
+	}
+	if err := rm.bufferPool.WritePage(pageID, sd.page); err != nil {
+		return RecordID{}, fmt.Errorf("failed to write page %d: %v", pageID, err)
+	}
+	return RecordID{PageID: pageID, SlotID: slotID}, nil
 }
 
-// ReadRecord reads and deserializes a record from a page
-func (rm *RecordManager) ReadRecord(pageID int, record interface{}) error {
-	data, err := rm.bufferPool.GetPage(pageID)
+// ReadRecord reads and decodes the record stored at id.
+func (rm *RecordManager) ReadRecord(id RecordID, record interface{}) error {
+	data, err := rm.bufferPool.GetPage(id.PageID)
 	if err != nil {
-		return fmt.Errorf("failed to read page %d: %v", pageID, err)
+		return fmt.Errorf("failed to read page %d: %v", id.PageID, err)
 	}
-
-	if err := Deserialize(data, record); err != nil {
-		return fmt.Errorf("failed to deserialize record from page %d: %v", pageID, err)
+	sd := decodeSlotDirectory(data)
+	raw, err := sd.get(id.SlotID)
+	if err != nil {
+		return fmt.Errorf("failed to read slot %d on page %d: %v", id.SlotID, id.PageID, err)
+	}
+	if err := rm.codec.Decode(raw, record); err != nil {
+		return fmt.Errorf("failed to decode record at page %d slot %d: %v", id.PageID, id.SlotID, err)
 	}
+	return nil
+}
 
+// DeleteRecord tombstones the slot at id, reclaiming its bytes in place
+// once the page's live fraction drops below slotCompactionThreshold.
+func (rm *RecordManager) DeleteRecord(id RecordID) error {
+	data, err := rm.bufferPool.GetPage(id.PageID)
+	if err != nil {
+		return fmt.Errorf("failed to read page %d: %v", id.PageID, err)
+	}
+	sd := decodeSlotDirectory(data)
+	if err := sd.tombstone(id.SlotID); err != nil {
+		return fmt.Errorf("failed to delete slot %d on page %d: %v", id.SlotID, id.PageID, err)
+	}
+	if sd.liveFraction() < slotCompactionThreshold {
+		sd.compact()
+	}
+	if err := rm.bufferPool.WritePage(id.PageID, sd.page); err != nil {
+		return fmt.Errorf("failed to write page %d: %v", id.PageID, err)
+	}
 	return nil
 }
@@ -0,0 +1,77 @@
+package graphdb
+
+import "fmt"
+
+// EventType identifies a lifecycle event GraphManager dispatches to
+// registered Rules. Each mutating GraphManager method (AddNode, AddEdge,
+// UpdateNode, UpdateEdge, DeleteNode, DeleteEdge) dispatches a Before*
+// event immediately before it touches the record manager and index, and
+// the matching non-Before event immediately after -- a Rule handling the
+// Before event can reject the mutation outright by returning an error,
+// before anything has been written.
+type EventType int
+
+const (
+	EventBeforeNodeCreated EventType = iota
+	EventNodeCreated
+	EventBeforeNodeUpdated
+	EventNodeUpdated
+	EventBeforeNodeDeleted
+	EventNodeDeleted
+	EventBeforeEdgeCreated
+	EventEdgeCreated
+	EventBeforeEdgeUpdated
+	EventEdgeUpdated
+	EventBeforeEdgeDeleted
+	EventEdgeDeleted
+)
+
+// ErrEventHandled is a sentinel a Rule's Handle can return to say it has
+// fully handled the event itself: dispatch stops calling any further
+// Rule registered for this EventType, but the mutation proceeds as if no
+// error occurred. Any other error aborts the enclosing mutation (a
+// Before* event) or the enclosing transaction (a post-write event, whose
+// write already reached the record manager and index -- TransactionManager's
+// rollback is what undoes it).
+var ErrEventHandled = fmt.Errorf("event handled")
+
+// Rule is a pluggable pre/post-write hook GraphManager dispatches graph
+// lifecycle events to, modeled on EliasDB's rule system. args carries
+// the Node or Edge the event concerns. txn is the Tx (see tx.go) the
+// triggering call came through, or nil when GraphManager was called
+// directly without one -- GraphManager has no transaction context of its
+// own, so every call site (e.g. the Cypher Executor, GraphDB.DeleteNodeCascade,
+// and Tx's AddNode/AddEdge/UpdateNode/DeleteNode, which apply immediately
+// through GraphManager) currently dispatches with txn == nil.
+type Rule interface {
+	Name() string
+	Handles() []EventType
+	Handle(gm *GraphManager, txn *Tx, event EventType, args ...interface{}) error
+}
+
+// RegisterRule adds r to the set of rules dispatched to for each
+// EventType in r.Handles(). Rules registered for the same EventType run
+// in registration order.
+func (gm *GraphManager) RegisterRule(r Rule) {
+	for _, event := range r.Handles() {
+		gm.rules[event] = append(gm.rules[event], r)
+	}
+}
+
+// dispatch runs every Rule registered for event, in registration order,
+// passing args through to Handle unchanged. A Rule returning
+// ErrEventHandled stops dispatch without that counting as a failure; any
+// other error is wrapped with the offending rule's name and returned to
+// the caller, which -- for a Before* event -- aborts the mutation before
+// it writes anything.
+func (gm *GraphManager) dispatch(txn *Tx, event EventType, args ...interface{}) error {
+	for _, r := range gm.rules[event] {
+		if err := r.Handle(gm, txn, event, args...); err != nil {
+			if err == ErrEventHandled {
+				return nil
+			}
+			return fmt.Errorf("rule %q rejected event: %v", r.Name(), err)
+		}
+	}
+	return nil
+}
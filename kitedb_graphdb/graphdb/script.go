@@ -0,0 +1,314 @@
+package graphdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// scriptEventSinks maps the event sink names .script files declare with
+// `on <sink>` to the EventType GraphManager.RegisterRule dispatches on
+// (see rules.go). "query.executed" isn't a GraphManager event -- it fires
+// after GraphDB.ExecuteQuery commits -- so it's handled separately by
+// ScriptRuntime.fireQueryExecuted rather than through this table.
+var scriptEventSinks = map[string]EventType{
+	"db.node.created": EventNodeCreated,
+	"db.node.updated": EventNodeUpdated,
+	"db.node.deleted": EventNodeDeleted,
+	"db.edge.created": EventEdgeCreated,
+	"db.edge.updated": EventEdgeUpdated,
+	"db.edge.deleted": EventEdgeDeleted,
+}
+
+const queryExecutedSink = "query.executed"
+
+// scriptStmt is one parsed statement of the form `receiver.method(args)`,
+// e.g. `graph.storeNode("Audit", "action", "created")`.
+type scriptStmt struct {
+	receiver string
+	method   string
+	args     []string // raw tokens, resolved by resolveArg when the statement runs
+}
+
+// compiledScript is one .script file: either bound to an event sink (via
+// a leading `on <sink>` line) or, for EntryScript, a flat statement list
+// run once at load time.
+type compiledScript struct {
+	name  string
+	sink  string
+	stmts []scriptStmt
+}
+
+// ScriptRuntime is KiteDB's embedded automation layer: NewGraphDBWithScripting
+// loads every *.script file in ScriptFolder, registers each against its
+// declared event sink, and runs EntryScript once immediately. It is
+// intentionally small -- a fixed `receiver.method("literal", ...)`
+// statement grammar with a handful of graph.* standard-library calls --
+// rather than a full embedded VM (Starlark, ECAL): this tree has no
+// third-party dependencies and no Go toolchain to vet one against, so
+// building a genuinely working minimal interpreter beats depending on a
+// library that can't be fetched here. See AttachDebugger for the one
+// piece of the request this honestly can't deliver.
+type ScriptRuntime struct {
+	db                  *GraphDB
+	queryExecutedScripts []compiledScript
+}
+
+// NewScriptRuntime loads and registers every *.script file in folder
+// (non-recursively), then runs entryScript once if given. entryScript is
+// resolved relative to folder unless it's already absolute. A parse or
+// execution error aborts the load -- same as this package's other "a
+// script/rule error aborts the change" conventions (see rules.go) --
+// rather than silently skipping a broken script.
+func NewScriptRuntime(db *GraphDB, folder, entryScript string) (*ScriptRuntime, error) {
+	rt := &ScriptRuntime{db: db}
+
+	if folder != "" {
+		entries, err := os.ReadDir(folder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script folder %q: %v", folder, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".script") {
+				continue
+			}
+			path := filepath.Join(folder, entry.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read script %q: %v", path, err)
+			}
+			script, err := parseScript(entry.Name(), string(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse script %q: %v", path, err)
+			}
+			if err := rt.register(script); err != nil {
+				return nil, fmt.Errorf("failed to register script %q: %v", path, err)
+			}
+		}
+	}
+
+	if entryScript != "" {
+		path := entryScript
+		if !filepath.IsAbs(path) && folder != "" {
+			path = filepath.Join(folder, entryScript)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read entry script %q: %v", path, err)
+		}
+		script, err := parseScript(path, string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse entry script %q: %v", path, err)
+		}
+		for _, stmt := range script.stmts {
+			if err := rt.exec(stmt, EventType(-1)); err != nil {
+				return nil, fmt.Errorf("entry script %q failed: %v", path, err)
+			}
+		}
+	}
+
+	return rt, nil
+}
+
+// register hooks script up to whatever it declared with `on <sink>`.
+func (rt *ScriptRuntime) register(script compiledScript) error {
+	if script.sink == "" {
+		return fmt.Errorf("script has no `on <event>` declaration")
+	}
+	if script.sink == queryExecutedSink {
+		rt.queryExecutedScripts = append(rt.queryExecutedScripts, script)
+		return nil
+	}
+	event, ok := scriptEventSinks[script.sink]
+	if !ok {
+		return fmt.Errorf("unknown event sink %q", script.sink)
+	}
+	rt.db.graph.RegisterRule(&scriptRule{script: script, event: event, rt: rt})
+	return nil
+}
+
+// fireQueryExecuted runs every script registered against "query.executed",
+// called by GraphDB.ExecuteQuery after a query commits successfully.
+func (rt *ScriptRuntime) fireQueryExecuted() error {
+	for _, script := range rt.queryExecutedScripts {
+		for _, stmt := range script.stmts {
+			if err := rt.exec(stmt, EventType(-1)); err != nil {
+				return fmt.Errorf("script %q failed: %v", script.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// scriptRule adapts one event-bound compiledScript to the Rule interface
+// (see rules.go) so GraphManager dispatches to it like any other rule.
+type scriptRule struct {
+	script compiledScript
+	event  EventType
+	rt     *ScriptRuntime
+}
+
+func (s *scriptRule) Name() string          { return s.script.name }
+func (s *scriptRule) Handles() []EventType  { return []EventType{s.event} }
+func (s *scriptRule) Handle(gm *GraphManager, txn *Tx, event EventType, args ...interface{}) error {
+	for _, stmt := range s.script.stmts {
+		if err := s.rt.exec(stmt, event, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exec runs one statement's graph.* standard-library call. event/args
+// are the triggering GraphManager event (if any), letting resolveArg
+// fill in the special "event.id" token.
+func (rt *ScriptRuntime) exec(stmt scriptStmt, event EventType, args ...interface{}) error {
+	if stmt.receiver != "graph" {
+		return fmt.Errorf("unknown receiver %q (only \"graph\" is supported)", stmt.receiver)
+	}
+
+	resolved := make([]string, len(stmt.args))
+	for i, raw := range stmt.args {
+		v, err := resolveArg(raw, args...)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", stmt.receiver, stmt.method, err)
+		}
+		resolved[i] = v
+	}
+
+	switch stmt.method {
+	case "storeNode":
+		// graph.storeNode(label, key, value) -- the minimal standard
+		// library's way to exercise GraphDB.AddNode from a script; richer
+		// property types aren't reachable from this grammar's
+		// string-literal-only arguments.
+		if len(resolved) != 3 {
+			return fmt.Errorf("graph.storeNode expects (label, key, value), got %d args", len(resolved))
+		}
+		node := Node{
+			Labels:     []string{resolved[0]},
+			Properties: []Property{NewStringProperty(resolved[1], resolved[2])},
+			Active:     true,
+		}
+		_, err := rt.db.graph.AddNode(node)
+		return err
+	case "getNode":
+		// graph.getNode(id) -- fetches the node for side-effecting
+		// validation (e.g. a rule that fails the mutation if a related
+		// node is missing). There's no variable binding in this grammar
+		// to capture the result further than that.
+		if len(resolved) != 1 {
+			return fmt.Errorf("graph.getNode expects (id), got %d args", len(resolved))
+		}
+		id, err := strconv.ParseInt(resolved[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("graph.getNode: invalid id %q: %v", resolved[0], err)
+		}
+		_, err = rt.db.graph.GetNode(id)
+		return err
+	case "query":
+		// graph.query(cypher) -- runs a Cypher statement inline, mapped to
+		// GraphDB.ExecuteQuery as the request asks; results are discarded
+		// since this grammar has nowhere to bind them.
+		if len(resolved) != 1 {
+			return fmt.Errorf("graph.query expects (cypher), got %d args", len(resolved))
+		}
+		_, err := rt.db.ExecuteQuery(resolved[0])
+		return err
+	default:
+		return fmt.Errorf("unknown graph.%s", stmt.method)
+	}
+}
+
+// resolveArg turns one raw argument token into its literal string value.
+// Supported forms: a double-quoted string literal, or the special token
+// "event.id" resolved from the first of args that's a Node or Edge.
+func resolveArg(raw string, args ...interface{}) (string, error) {
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		return raw[1 : len(raw)-1], nil
+	}
+	if raw == "event.id" {
+		for _, a := range args {
+			switch v := a.(type) {
+			case Node:
+				return strconv.FormatInt(v.ID, 10), nil
+			case Edge:
+				return strconv.FormatInt(v.ID, 10), nil
+			}
+		}
+		return "", fmt.Errorf("event.id: no triggering node or edge available")
+	}
+	return "", fmt.Errorf("unsupported argument %q (only string literals and event.id are supported)", raw)
+}
+
+// parseScript parses one .script file's text into a compiledScript.
+// Grammar:
+//
+//	[on <sink>]
+//	receiver.method("arg", "arg", ...)
+//	...
+//	[end]
+//
+// The `on`/`end` lines are optional -- EntryScript's file is a flat
+// statement list with no sink -- but a script loaded from ScriptFolder
+// must declare one or register returns an error. Blank lines and lines
+// starting with "#" are ignored.
+func parseScript(name, text string) (compiledScript, error) {
+	script := compiledScript{name: name}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "on ") {
+			script.sink = strings.TrimSpace(strings.TrimPrefix(line, "on "))
+			continue
+		}
+		if line == "end" {
+			continue
+		}
+		stmt, err := parseStmt(line)
+		if err != nil {
+			return compiledScript{}, fmt.Errorf("line %q: %v", line, err)
+		}
+		script.stmts = append(script.stmts, stmt)
+	}
+	return script, nil
+}
+
+// parseStmt parses one `receiver.method(arg, arg, ...)` line.
+func parseStmt(line string) (scriptStmt, error) {
+	open := strings.IndexByte(line, '(')
+	if open < 0 || !strings.HasSuffix(line, ")") {
+		return scriptStmt{}, fmt.Errorf("expected receiver.method(args)")
+	}
+	head := line[:open]
+	dot := strings.IndexByte(head, '.')
+	if dot < 0 {
+		return scriptStmt{}, fmt.Errorf("expected receiver.method(args)")
+	}
+	stmt := scriptStmt{receiver: head[:dot], method: head[dot+1:]}
+
+	argsPart := strings.TrimSpace(line[open+1 : len(line)-1])
+	if argsPart == "" {
+		return stmt, nil
+	}
+	for _, arg := range strings.Split(argsPart, ",") {
+		stmt.args = append(stmt.args, strings.TrimSpace(arg))
+	}
+	return stmt, nil
+}
+
+// AttachDebugger is the request's "debug port that can attach a stepping
+// debugger to running scripts". A real stepping debugger needs a wire
+// protocol, breakpoints, and a paused-goroutine inspection model -- well
+// beyond what this package's fixed statement grammar or a stdlib-only,
+// no-toolchain sandbox can honestly deliver. It's left as an explicit
+// unavailable stub, in the same spirit as ProtoCodec (codec.go) and
+// SnappyPageCodec/ZstdPageCodec (storage.go), rather than a fake
+// implementation that can't actually step anything.
+func (rt *ScriptRuntime) AttachDebugger(addr string) error {
+	return fmt.Errorf("script debugger not available: no stepping VM in this build")
+}
@@ -0,0 +1,258 @@
+package graphdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Server exposes GraphDB over the network so multiple clients can connect
+// concurrently. Each database is guarded by its own mutex (BoltDB-style)
+// so one slow query against one database never blocks another.
+type Server struct {
+	dataDir string
+
+	mu        sync.RWMutex // guards databases and locks maps
+	databases map[string]*GraphDB
+	locks     map[string]*sync.Mutex
+}
+
+// NewServer initializes a Server rooted at dataDir, where each database is
+// stored as "<dataDir>/<name>.db".
+func NewServer(dataDir string) *Server {
+	return &Server{
+		dataDir:   dataDir,
+		databases: make(map[string]*GraphDB),
+		locks:     make(map[string]*sync.Mutex),
+	}
+}
+
+// lockFor returns the per-database mutex, creating it if necessary.
+func (s *Server) lockFor(name string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, exists := s.locks[name]
+	if !exists {
+		l = &sync.Mutex{}
+		s.locks[name] = l
+	}
+	return l
+}
+
+// OpenDatabase opens (creating if needed) the named database.
+func (s *Server) OpenDatabase(name string) error {
+	lock := s.lockFor(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	s.mu.RLock()
+	_, exists := s.databases[name]
+	s.mu.RUnlock()
+	if exists {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dataDir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %v", err)
+	}
+	db, err := NewGraphDB(filepath.Join(s.dataDir, name+".db"), 4096, 100)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %v", name, err)
+	}
+
+	s.mu.Lock()
+	s.databases[name] = db
+	s.mu.Unlock()
+	return nil
+}
+
+// DropDatabase closes and deletes the named database.
+func (s *Server) DropDatabase(name string) error {
+	lock := s.lockFor(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	s.mu.Lock()
+	db, exists := s.databases[name]
+	if exists {
+		delete(s.databases, name)
+	}
+	s.mu.Unlock()
+
+	if exists {
+		if err := db.Close(); err != nil {
+			return fmt.Errorf("failed to close database %s: %v", name, err)
+		}
+	}
+
+	path := filepath.Join(s.dataDir, name+".db")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("database %s does not exist", name)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete database file: %v", err)
+	}
+	return nil
+}
+
+// ListDatabases returns the names of every database file under dataDir.
+func (s *Server) ListDatabases() ([]string, error) {
+	files, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read data directory: %v", err)
+	}
+	names := []string{}
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == ".db" {
+			names = append(names, file.Name()[:len(file.Name())-len(".db")])
+		}
+	}
+	return names, nil
+}
+
+// ExecuteQuery runs a Cypher query against the named database, opening it
+// first if necessary, serialized behind that database's mutex.
+func (s *Server) ExecuteQuery(name, query string) ([]map[string]interface{}, error) {
+	if err := s.OpenDatabase(name); err != nil {
+		return nil, err
+	}
+	lock := s.lockFor(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	s.mu.RLock()
+	db := s.databases[name]
+	s.mu.RUnlock()
+
+	return db.ExecuteQuery(query)
+}
+
+// ExecuteQueryStream runs query and delivers result rows one at a time on
+// rows as they become available, mirroring GraphDB.ExecuteQuery but suited
+// to a streaming RPC such as ExecuteQueryStream.
+func (s *Server) ExecuteQueryStream(name, query string, rows chan<- map[string]interface{}) error {
+	defer close(rows)
+	results, err := s.ExecuteQuery(name, query)
+	if err != nil {
+		return err
+	}
+	for _, row := range results {
+		rows <- row
+	}
+	return nil
+}
+
+// Close shuts down every open database.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, db := range s.databases {
+		if err := db.Close(); err != nil {
+			return fmt.Errorf("failed to close database %s: %v", name, err)
+		}
+		delete(s.databases, name)
+	}
+	return nil
+}
+
+// httpGateway adapts Server onto a plain HTTP/JSON API so non-Go clients
+// (curl, scripts) can drive it without speaking the RPC wire protocol
+// directly. It is deliberately thin: one handler per RPC.
+type httpGateway struct {
+	server *Server
+}
+
+// NewHTTPGateway wraps server as an http.Handler exposing:
+//
+//	GET  /databases                 -> ListDatabases
+//	POST /databases/{name}          -> OpenDatabase
+//	DELETE /databases/{name}        -> DropDatabase
+//	POST /databases/{name}/query    -> ExecuteQuery (body: {"query": "..."})
+func NewHTTPGateway(server *Server) http.Handler {
+	return &httpGateway{server: server}
+}
+
+func (g *httpGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/databases" && r.Method == http.MethodGet {
+		names, err := g.server.ListDatabases()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, names)
+		return
+	}
+
+	name, rest := splitDatabasePath(r.URL.Path)
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case rest == "" && r.Method == http.MethodPost:
+		if err := g.server.OpenDatabase(name); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "opened"})
+	case rest == "" && r.Method == http.MethodDelete:
+		if err := g.server.DropDatabase(name); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "dropped"})
+	case rest == "/query" && r.Method == http.MethodPost:
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+			return
+		}
+		results, err := g.server.ExecuteQuery(name, body.Query)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, results)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// splitDatabasePath parses "/databases/<name>[/query]" into its name and
+// trailing segment.
+func splitDatabasePath(path string) (name, rest string) {
+	const prefix = "/databases/"
+	if len(path) <= len(prefix) || path[:len(prefix)] != prefix {
+		return "", ""
+	}
+	tail := path[len(prefix):]
+	for i := 0; i < len(tail); i++ {
+		if tail[i] == '/' {
+			return tail[:i], tail[i:]
+		}
+	}
+	return tail, ""
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.WithError(err).Error("failed to encode JSON response")
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -1,25 +1,221 @@
 package graphdb
 
 import (
+	"bytes"
+	"compress/flate"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
 	"os"
 )
 
-// StorageManager handles disk I/O for the database
+// ErrPageCorrupt is returned by ReadPage when a page's stored CRC
+// doesn't match its contents -- bit rot, a torn write, or disk-level
+// corruption, as opposed to the os.ErrInvalid a caller gets for an
+// out-of-range pageID or a WritePage of the wrong size.
+var ErrPageCorrupt = errors.New("graphdb: page failed CRC verification")
+
+// pageTrailerSize is the fixed footer every on-disk page carries,
+// following its (possibly compressed) payload: codec_id (1 byte),
+// uncompressed_len (4 bytes), flags (1 byte), 6 bytes reserved, and
+// crc32c (4 bytes) -- 1+4+1+6+4 = 16.
+const pageTrailerSize = 16
+
+// castagnoliTable computes the crc32c checksum the page trailer stores
+// -- a different polynomial than wal_log.go's CRC32 (IEEE) frames,
+// matching the checksum page-oriented file formats conventionally use.
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// pageTrailer is the parsed form of a page's 16-byte trailer; see
+// pageTrailerSize.
+type pageTrailer struct {
+	codecID         uint8
+	uncompressedLen uint32
+	flags           uint8
+	crc32c          uint32
+}
+
+// encodeTrailer serializes t into pageTrailerSize bytes.
+func encodeTrailer(t pageTrailer) []byte {
+	buf := make([]byte, pageTrailerSize)
+	buf[0] = t.codecID
+	binary.LittleEndian.PutUint32(buf[1:5], t.uncompressedLen)
+	buf[5] = t.flags
+	// buf[6:12] is reserved, left zero.
+	binary.LittleEndian.PutUint32(buf[12:16], t.crc32c)
+	return buf
+}
+
+// decodeTrailer parses a pageTrailerSize-byte trailer.
+func decodeTrailer(buf []byte) pageTrailer {
+	return pageTrailer{
+		codecID:         buf[0],
+		uncompressedLen: binary.LittleEndian.Uint32(buf[1:5]),
+		flags:           buf[5],
+		crc32c:          binary.LittleEndian.Uint32(buf[12:16]),
+	}
+}
+
+// PageCodec converts a logical, exactly-pageSize-byte page to and from
+// the (possibly smaller) bytes StorageManager stores on disk ahead of
+// the page trailer. Encode's output must fit within pageSize bytes --
+// StorageManager zero-pads anything shorter and rejects anything
+// longer, the same way record.go rejects a record that doesn't fit a
+// page.
+type PageCodec interface {
+	// ID identifies the codec in the page trailer and file header, so a
+	// file opened with a different codec than the one it was written
+	// with fails fast instead of silently misreading pages.
+	ID() uint8
+	Encode(raw []byte) ([]byte, error)
+	Decode(stored []byte) ([]byte, error)
+}
+
+// pageCodecByID resolves the codec a page (or the file header) claims,
+// for ReadPage and NewStorageManagerWithCodec to validate against.
+func pageCodecByID(id uint8) (PageCodec, error) {
+	switch id {
+	case RawCodecID:
+		return RawPageCodec{}, nil
+	case FlateCodecID:
+		return FlatePageCodec{}, nil
+	case SnappyCodecID:
+		return SnappyPageCodec{}, nil
+	case ZstdCodecID:
+		return ZstdPageCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown page codec id %d", id)
+	}
+}
+
+// Page codec IDs, recorded in the file header and every page trailer so
+// a file written with one codec can't be silently misread with another.
+const (
+	RawCodecID    uint8 = 0
+	FlateCodecID  uint8 = 1
+	SnappyCodecID uint8 = 2
+	ZstdCodecID   uint8 = 3
+)
+
+// RawPageCodec stores pages uncompressed -- StorageManager's default.
+// Pages still carry the 16-byte trailer every codec gets, so a file
+// written before PageCodec existed (pageSize stride, no trailer) does not
+// read back with it.
+type RawPageCodec struct{}
+
+func (RawPageCodec) ID() uint8 { return RawCodecID }
+
+func (RawPageCodec) Encode(raw []byte) ([]byte, error) { return raw, nil }
+
+func (RawPageCodec) Decode(stored []byte) ([]byte, error) { return stored, nil }
+
+// FlatePageCodec compresses pages with the standard library's
+// compress/flate. It fills the role chunk3-4 asked Snappy and Zstd to
+// play -- this repository has no go.mod and vendors nothing, so neither
+// is actually available (the same gap that left ProtoCodec, in
+// codec.go, unable to encode anything); FlatePageCodec is real,
+// stdlib-only compression rather than a second unavailable stub.
+type FlatePageCodec struct{}
+
+func (FlatePageCodec) ID() uint8 { return FlateCodecID }
+
+func (FlatePageCodec) Encode(raw []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open flate writer: %v", err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return nil, fmt.Errorf("failed to flate-compress page: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to flush flate writer: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (FlatePageCodec) Decode(stored []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(stored))
+	defer r.Close()
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flate-decompress page: %v", err)
+	}
+	return raw, nil
+}
+
+// errSnappyCodecUnavailable/errZstdCodecUnavailable explain why
+// SnappyPageCodec/ZstdPageCodec can't actually encode or decode
+// anything in this build -- see FlatePageCodec's doc comment.
+var (
+	errSnappyCodecUnavailable = fmt.Errorf("SnappyPageCodec requires github.com/golang/snappy, which this build does not vendor -- use FlatePageCodec or RawPageCodec instead")
+	errZstdCodecUnavailable   = fmt.Errorf("ZstdPageCodec requires github.com/klauspost/compress/zstd, which this build does not vendor -- use FlatePageCodec or RawPageCodec instead")
+)
+
+// SnappyPageCodec is the PageCodec StorageManager will use once this
+// repository vendors a Snappy implementation. Neither is available in
+// this build, so it reports that honestly rather than guessing at a
+// wire format nothing here can actually decode.
+type SnappyPageCodec struct{}
+
+func (SnappyPageCodec) ID() uint8 { return SnappyCodecID }
+
+func (SnappyPageCodec) Encode(raw []byte) ([]byte, error) { return nil, errSnappyCodecUnavailable }
+
+func (SnappyPageCodec) Decode(stored []byte) ([]byte, error) { return nil, errSnappyCodecUnavailable }
+
+// ZstdPageCodec is the PageCodec StorageManager will use once this
+// repository vendors a Zstd implementation. See SnappyPageCodec.
+type ZstdPageCodec struct{}
+
+func (ZstdPageCodec) ID() uint8 { return ZstdCodecID }
+
+func (ZstdPageCodec) Encode(raw []byte) ([]byte, error) { return nil, errZstdCodecUnavailable }
+
+func (ZstdPageCodec) Decode(stored []byte) ([]byte, error) { return nil, errZstdCodecUnavailable }
+
+// StorageManager handles disk I/O for the database. Every logical page
+// it hands callers is exactly pageSize bytes; on disk, each occupies
+// pageSize+pageTrailerSize bytes, the codec's (possibly smaller, always
+// zero-padded back up to pageSize) output followed by its trailer. See
+// PageCodec.
 type StorageManager struct {
 	file     *os.File
+	filename string
 	pageSize int
 	numPages int
+	codec    PageCodec
+}
+
+// diskStride is the byte span one page occupies on disk, payload plus
+// trailer.
+func (sm *StorageManager) diskStride() int64 {
+	return int64(sm.pageSize) + pageTrailerSize
 }
 
-// NewStorageManager initializes a new StorageManager
+// NewStorageManager initializes a new StorageManager using RawPageCodec
+// -- no compression, but with the same per-page trailer and CRC as any
+// other codec.
 func NewStorageManager(filename string, pageSize int) (*StorageManager, error) {
+	return NewStorageManagerWithCodec(filename, pageSize, RawPageCodec{})
+}
 
+// NewStorageManagerWithCodec initializes a new StorageManager that
+// encodes every page through codec. Opening a file that already exists
+// validates codec against the codec ID recorded in its header and
+// fails with a clear error on a mismatch, rather than silently
+// misreading pages a different codec wrote.
+func NewStorageManagerWithCodec(filename string, pageSize int, codec PageCodec) (*StorageManager, error) {
 	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0666)
 	if err != nil {
 		return nil, err
 	}
 
+	sm := &StorageManager{file: file, filename: filename, pageSize: pageSize, codec: codec}
+	stride := sm.diskStride()
+
 	fileInfo, err := file.Stat()
 	if err != nil {
 		file.Close()
@@ -27,54 +223,107 @@ func NewStorageManager(filename string, pageSize int) (*StorageManager, error) {
 	}
 	fileSize := fileInfo.Size()
 
-	numPages := int(fileSize / int64(pageSize))
-	if fileSize%int64(pageSize) != 0 {
+	numPages := int(fileSize / stride)
+	if fileSize%stride != 0 {
 		file.Close()
 		return nil, os.ErrInvalid
 	}
 
 	if fileSize == 0 {
-		header := make([]byte, pageSize)
+		header := make([]byte, stride)
 		copy(header[0:4], []byte("GDB\000"))
 		binary.LittleEndian.PutUint32(header[4:8], uint32(pageSize))
 		binary.LittleEndian.PutUint32(header[8:12], uint32(numPages))
-		_, err = file.WriteAt(header, 0)
-		if err != nil {
+		header[12] = codec.ID()
+		if _, err := file.WriteAt(header, 0); err != nil {
 			file.Close()
 			return nil, err
 		}
 		numPages = 1
+	} else {
+		header := make([]byte, stride)
+		if _, err := file.ReadAt(header, 0); err != nil {
+			file.Close()
+			return nil, err
+		}
+		if header[12] != codec.ID() {
+			file.Close()
+			return nil, fmt.Errorf("file %s was written with page codec id %d, but codec id %d was requested", filename, header[12], codec.ID())
+		}
 	}
 
-	return &StorageManager{
-		file:     file,
-		pageSize: pageSize,
-		numPages: numPages,
-	}, nil
+	sm.numPages = numPages
+	return sm, nil
+}
+
+// PageCount returns the total number of pages in the file, including the
+// header page.
+func (sm *StorageManager) PageCount() int {
+	return sm.numPages
 }
 
-// ReadPage reads a page from disk
+// ReadPage reads a page from disk, verifying its trailer's crc32c and
+// decoding it back to its original pageSize-byte form with the codec
+// its trailer names. A CRC mismatch reports ErrPageCorrupt rather than
+// handing back bytes that silently don't match what was written.
 func (sm *StorageManager) ReadPage(pageID int) ([]byte, error) {
 	if pageID < 0 || pageID >= sm.numPages {
 		return nil, os.ErrInvalid
 	}
 
-	data := make([]byte, sm.pageSize)
-	_, err := sm.file.ReadAt(data, int64(pageID)*int64(sm.pageSize))
-	if err != nil {
+	full := make([]byte, sm.diskStride())
+	if _, err := sm.file.ReadAt(full, int64(pageID)*sm.diskStride()); err != nil {
 		return nil, err
 	}
-	return data, nil
+
+	stored := full[:sm.pageSize]
+	trailer := decodeTrailer(full[sm.pageSize:])
+
+	if crc32.Checksum(stored, castagnoliTable) != trailer.crc32c {
+		return nil, ErrPageCorrupt
+	}
+
+	codec, err := pageCodecByID(trailer.codecID)
+	if err != nil {
+		return nil, fmt.Errorf("page %d: %v", pageID, err)
+	}
+	raw, err := codec.Decode(stored)
+	if err != nil {
+		return nil, fmt.Errorf("page %d: %v", pageID, err)
+	}
+	if uint32(len(raw)) != trailer.uncompressedLen {
+		return nil, fmt.Errorf("page %d: decoded to %d bytes, trailer expects %d", pageID, len(raw), trailer.uncompressedLen)
+	}
+	return raw, nil
 }
 
-// WritePage writes a page to disk
+// WritePage encodes data with the StorageManager's codec, zero-pads the
+// result to pageSize bytes, appends a trailer carrying its crc32c, and
+// writes the whole on-disk stride in one call.
 func (sm *StorageManager) WritePage(pageID int, data []byte) error {
 	if pageID < 0 || pageID >= sm.numPages || len(data) != sm.pageSize {
 		return os.ErrInvalid
 	}
 
-	_, err := sm.file.WriteAt(data, int64(pageID)*int64(sm.pageSize))
+	encoded, err := sm.codec.Encode(data)
 	if err != nil {
+		return fmt.Errorf("page %d: failed to encode: %v", pageID, err)
+	}
+	if len(encoded) > sm.pageSize {
+		return fmt.Errorf("page %d: encoded size %d exceeds page capacity %d", pageID, len(encoded), sm.pageSize)
+	}
+
+	stored := make([]byte, sm.pageSize)
+	copy(stored, encoded)
+
+	trailer := encodeTrailer(pageTrailer{
+		codecID:         sm.codec.ID(),
+		uncompressedLen: uint32(len(data)),
+		crc32c:          crc32.Checksum(stored, castagnoliTable),
+	})
+
+	full := append(stored, trailer...)
+	if _, err := sm.file.WriteAt(full, int64(pageID)*sm.diskStride()); err != nil {
 		return err
 	}
 	return nil
@@ -83,22 +332,19 @@ func (sm *StorageManager) WritePage(pageID int, data []byte) error {
 // AllocatePage allocates a new page
 func (sm *StorageManager) AllocatePage() (int, error) {
 	pageID := sm.numPages
-	newPage := make([]byte, sm.pageSize)
-	_, err := sm.file.WriteAt(newPage, int64(pageID)*int64(sm.pageSize))
-	if err != nil {
+	newPage := make([]byte, sm.diskStride())
+	if _, err := sm.file.WriteAt(newPage, int64(pageID)*sm.diskStride()); err != nil {
 		return -1, err
 	}
 	sm.numPages++
 
 	// Update header with new numPages
-	header := make([]byte, sm.pageSize)
-	_, err = sm.file.ReadAt(header, 0)
-	if err != nil {
+	header := make([]byte, sm.diskStride())
+	if _, err := sm.file.ReadAt(header, 0); err != nil {
 		return -1, err
 	}
 	binary.LittleEndian.PutUint32(header[8:12], uint32(sm.numPages))
-	_, err = sm.file.WriteAt(header, 0)
-	if err != nil {
+	if _, err := sm.file.WriteAt(header, 0); err != nil {
 		return -1, err
 	}
 	return pageID, nil
@@ -20,10 +20,14 @@ const (
 	TokenEOF
 )
 
-// Token represents a lexical token
+// Token represents a lexical token. Line and Col are 1-based and mark
+// where the token starts, so ParseErrors can point a user at the exact
+// spot a syntax error occurred.
 type Token struct {
 	Type  TokenType
 	Value string
+	Line  int
+	Col   int
 }
 
 // Tokenizer breaks a query into tokens
@@ -60,7 +64,8 @@ func (t *Tokenizer) Tokenize() []Token {
 			t.readSymbol()
 		}
 	}
-	t.tokens = append(t.tokens, Token{Type: TokenEOF, Value: ""})
+	eofLine, eofCol := lineCol(t.input, t.pos)
+	t.tokens = append(t.tokens, Token{Type: TokenEOF, Value: "", Line: eofLine, Col: eofCol})
 	log.WithField("token_count", len(t.tokens)).Info("Tokenization complete")
 	// Debug: Log all tokens
 	tokenList := make([]string, len(t.tokens))
@@ -71,6 +76,29 @@ func (t *Tokenizer) Tokenize() []Token {
 	return t.tokens
 }
 
+// lineCol returns the 1-based line and column of byte offset pos in s.
+func lineCol(s string, pos int) (int, int) {
+	line, col := 1, 1
+	if pos > len(s) {
+		pos = len(s)
+	}
+	for i := 0; i < pos; i++ {
+		if s[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// emit appends a token of typ/value starting at byte offset start.
+func (t *Tokenizer) emit(typ TokenType, value string, start int) {
+	line, col := lineCol(t.input, start)
+	t.tokens = append(t.tokens, Token{Type: typ, Value: value, Line: line, Col: col})
+}
+
 // readIdentifierOrKeyword reads an identifier or keyword
 func (t *Tokenizer) readIdentifierOrKeyword() {
 	start := t.pos
@@ -80,14 +108,18 @@ func (t *Tokenizer) readIdentifierOrKeyword() {
 	value := t.input[start:t.pos]
 	upperValue := strings.ToUpper(value)
 	tokenType := TokenIdentifier
-	if upperValue == "CREATE" || upperValue == "MATCH" || upperValue == "SET" || upperValue == "DELETE" || upperValue == "RETURN" || upperValue == "WHERE" {
+	switch upperValue {
+	case "CREATE", "MATCH", "SET", "DELETE", "RETURN", "WHERE", "DETACH",
+		"AND", "OR", "NOT", "IN", "IS", "NULL",
+		"STARTS", "ENDS", "WITH", "CONTAINS", "FULLTEXT":
 		tokenType = TokenKeyword
 	}
-	t.tokens = append(t.tokens, Token{Type: tokenType, Value: value})
+	t.emit(tokenType, value, start)
 }
 
 // readString reads a quoted string
 func (t *Tokenizer) readString() {
+	tokStart := t.pos
 	t.pos++ // Skip opening quote
 	start := t.pos
 	for t.pos < len(t.input) && t.input[t.pos] != '"' {
@@ -95,59 +127,113 @@ func (t *Tokenizer) readString() {
 	}
 	value := t.input[start:t.pos]
 	t.pos++ // Skip closing quote
-	t.tokens = append(t.tokens, Token{Type: TokenString, Value: value})
+	t.emit(TokenString, value, tokStart)
 }
 
-// readNumber reads a number
+// readNumber reads an integer or, if a '.' is followed by another digit,
+// a float. A lone '.' (or one starting ".." as in a var-length bound like
+// `1..3`) stops the integer at the digits already read, leaving the '.'
+// for readSymbol to tokenize.
 func (t *Tokenizer) readNumber() {
 	start := t.pos
 	for t.pos < len(t.input) && unicode.IsDigit(rune(t.input[t.pos])) {
 		t.pos++
 	}
+	if t.pos+1 < len(t.input) && t.input[t.pos] == '.' && unicode.IsDigit(rune(t.input[t.pos+1])) {
+		t.pos++ // consume '.'
+		for t.pos < len(t.input) && unicode.IsDigit(rune(t.input[t.pos])) {
+			t.pos++
+		}
+	}
 	value := t.input[start:t.pos]
-	t.tokens = append(t.tokens, Token{Type: TokenNumber, Value: value})
+	t.emit(TokenNumber, value, start)
 }
 
 // readSymbol reads a symbol or operator
 func (t *Tokenizer) readSymbol() {
+	start := t.pos
 	switch t.input[t.pos] {
 	case '(':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "("})
+		t.emit(TokenSymbol, "(", start)
 		t.pos++
 	case ')':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: ")"})
+		t.emit(TokenSymbol, ")", start)
 		t.pos++
 	case '{':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "{"})
+		t.emit(TokenSymbol, "{", start)
 		t.pos++
 	case '}':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "}"})
+		t.emit(TokenSymbol, "}", start)
 		t.pos++
 	case ':':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: ":"})
+		t.emit(TokenSymbol, ":", start)
+		t.pos++
+	case ';':
+		t.emit(TokenSymbol, ";", start)
 		t.pos++
 	case ',':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: ","})
+		t.emit(TokenSymbol, ",", start)
 		t.pos++
 	case '=':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "="})
-		t.pos++
+		if t.pos+1 < len(t.input) && t.input[t.pos+1] == '~' {
+			t.emit(TokenSymbol, "=~", start)
+			t.pos += 2
+		} else {
+			t.emit(TokenSymbol, "=", start)
+			t.pos++
+		}
 	case '-':
 		if t.pos+2 < len(t.input) && t.input[t.pos+1] == '>' {
-			t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "->"})
+			t.emit(TokenSymbol, "->", start)
 			t.pos += 2
 		} else {
-			t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "-"})
+			t.emit(TokenSymbol, "-", start)
+			t.pos++
+		}
+	case '<':
+		if t.pos+1 < len(t.input) && t.input[t.pos+1] == '-' {
+			t.emit(TokenSymbol, "<-", start)
+			t.pos += 2
+		} else if t.pos+1 < len(t.input) && t.input[t.pos+1] == '>' {
+			t.emit(TokenSymbol, "<>", start)
+			t.pos += 2
+		} else if t.pos+1 < len(t.input) && t.input[t.pos+1] == '=' {
+			t.emit(TokenSymbol, "<=", start)
+			t.pos += 2
+		} else {
+			t.emit(TokenSymbol, "<", start)
+			t.pos++
+		}
+	case '>':
+		if t.pos+1 < len(t.input) && t.input[t.pos+1] == '=' {
+			t.emit(TokenSymbol, ">=", start)
+			t.pos += 2
+		} else {
+			t.emit(TokenSymbol, ">", start)
 			t.pos++
 		}
 	case '[':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "["})
+		t.emit(TokenSymbol, "[", start)
 		t.pos++
 	case ']':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "]"})
+		t.emit(TokenSymbol, "]", start)
 		t.pos++
 	case '.':
-		t.tokens = append(t.tokens, Token{Type: TokenSymbol, Value: "."})
+		if t.pos+1 < len(t.input) && t.input[t.pos+1] == '.' {
+			t.emit(TokenSymbol, "..", start)
+			t.pos += 2
+		} else {
+			t.emit(TokenSymbol, ".", start)
+			t.pos++
+		}
+	case '*':
+		t.emit(TokenSymbol, "*", start)
+		t.pos++
+	case '+':
+		t.emit(TokenSymbol, "+", start)
+		t.pos++
+	case '@':
+		t.emit(TokenSymbol, "@", start)
 		t.pos++
 	default:
 		logrus.WithField("char", string(t.input[t.pos])).Warn("Unknown symbol, skipping")
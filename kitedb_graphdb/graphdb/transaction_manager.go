@@ -16,12 +16,29 @@ const (
 	OpDeleteEdge
 )
 
-// TransactionOperation represents a transaction operation
+// TransactionOperation represents a transaction operation, along with
+// enough of a before-image to reverse it. Before is the full prior
+// property list for OpUpdateNode/OpUpdateEdge -- a merge-by-key update
+// can add a key that didn't exist before, so undoing it has to replace
+// the property list wholesale rather than merge a delta back in. Node
+// and Edge are the complete record as it stood just before
+// OpDeleteNode/OpDeleteEdge -- deleting fully removes the index entry
+// (see GraphManager.DeleteNode), so there's nothing left to reactivate
+// and undo has to re-create the record from this snapshot instead.
 type TransactionOperation struct {
 	Type       OperationType
 	NodeID     int64
 	EdgeID     int64
 	Properties []Property
+	Before     []Property
+	Node       Node
+	Edge       Edge
+	// WeightChanged marks an OpUpdateNode/OpUpdateEdge that came from
+	// IncrementNodeWeight/IncrementEdgeWeight/UpsertEdgeWeight rather than
+	// an ordinary property SET, so undoOperation knows to also restore
+	// BeforeWeight -- most updates never touch Weight and leave it alone.
+	WeightChanged bool
+	BeforeWeight  float64
 }
 
 // TransactionManager manages transactions
@@ -45,6 +62,7 @@ func (tm *TransactionManager) BeginTransaction() int64 {
 	txnID := tm.nextTxnID
 	tm.nextTxnID++
 	tm.operations[txnID] = []TransactionOperation{}
+	_ = tm.wal.Begin(txnID) // best-effort marker frame; Recover derives open txns from missing COMMIT frames regardless
 	return txnID
 }
 
@@ -54,7 +72,7 @@ func (tm *TransactionManager) RecordOperation(txnID int64, op TransactionOperati
 		return fmt.Errorf("transaction %d not found", txnID)
 	}
 	tm.operations[txnID] = append(tm.operations[txnID], op)
-	if err := tm.wal.LogOperation(op); err != nil {
+	if err := tm.wal.LogOperation(txnID, op); err != nil {
 		return fmt.Errorf("failed to log operation to WAL: %v", err)
 	}
 	fmt.Println("Operation recorded")
@@ -72,3 +90,121 @@ func (tm *TransactionManager) CommitTransaction(txnID int64) error {
 	delete(tm.operations, txnID)
 	return nil
 }
+
+// RollbackTransaction undoes every operation recorded for txnID, in
+// reverse order, and discards the transaction.
+func (tm *TransactionManager) RollbackTransaction(txnID int64, graph *GraphManager) error {
+	ops, exists := tm.operations[txnID]
+	if !exists {
+		return fmt.Errorf("transaction %d not found", txnID)
+	}
+
+	for i := len(ops) - 1; i >= 0; i-- {
+		if err := undoOperation(ops[i], graph); err != nil {
+			return err
+		}
+	}
+
+	delete(tm.operations, txnID)
+	return nil
+}
+
+// Savepoint marks the current point in txnID's operation log and returns
+// it as an opaque index for a later RollbackTo. Savepoints nest: rolling
+// back to an earlier one also undoes any later one taken inside it.
+func (tm *TransactionManager) Savepoint(txnID int64) (int, error) {
+	ops, exists := tm.operations[txnID]
+	if !exists {
+		return 0, fmt.Errorf("transaction %d not found", txnID)
+	}
+	return len(ops), nil
+}
+
+// RollbackTo undoes every operation recorded after savepoint sp, in
+// reverse order, without ending txnID -- further statements can still run
+// against it afterward.
+func (tm *TransactionManager) RollbackTo(txnID int64, sp int, graph *GraphManager) error {
+	ops, exists := tm.operations[txnID]
+	if !exists {
+		return fmt.Errorf("transaction %d not found", txnID)
+	}
+	if sp < 0 || sp > len(ops) {
+		return fmt.Errorf("invalid savepoint %d for transaction %d", sp, txnID)
+	}
+
+	for i := len(ops) - 1; i >= sp; i-- {
+		if err := undoOperation(ops[i], graph); err != nil {
+			return err
+		}
+	}
+
+	tm.operations[txnID] = ops[:sp]
+	return nil
+}
+
+// undoOperation reverses a single recorded operation against graph.
+func undoOperation(op TransactionOperation, graph *GraphManager) error {
+	switch op.Type {
+	case OpAddNode:
+		if err := graph.DeleteNode(op.NodeID); err != nil {
+			return fmt.Errorf("failed to undo creation of node %d: %v", op.NodeID, err)
+		}
+	case OpAddEdge:
+		if err := graph.DeleteEdge(op.EdgeID); err != nil {
+			return fmt.Errorf("failed to undo creation of edge %d: %v", op.EdgeID, err)
+		}
+	case OpUpdateNode:
+		if err := graph.ReplaceNodeProperties(op.NodeID, op.Before); err != nil {
+			return fmt.Errorf("failed to undo update of node %d: %v", op.NodeID, err)
+		}
+		if op.WeightChanged {
+			if err := graph.SetNodeWeight(op.NodeID, op.BeforeWeight); err != nil {
+				return fmt.Errorf("failed to undo weight update of node %d: %v", op.NodeID, err)
+			}
+		}
+	case OpUpdateEdge:
+		if err := graph.ReplaceEdgeProperties(op.EdgeID, op.Before); err != nil {
+			return fmt.Errorf("failed to undo update of edge %d: %v", op.EdgeID, err)
+		}
+		if op.WeightChanged {
+			if err := graph.SetEdgeWeight(op.EdgeID, op.BeforeWeight); err != nil {
+				return fmt.Errorf("failed to undo weight update of edge %d: %v", op.EdgeID, err)
+			}
+		}
+	case OpDeleteNode:
+		if err := graph.RestoreNode(op.Node); err != nil {
+			return fmt.Errorf("failed to undo deletion of node %d: %v", op.NodeID, err)
+		}
+	case OpDeleteEdge:
+		if err := graph.RestoreEdge(op.Edge); err != nil {
+			return fmt.Errorf("failed to undo deletion of edge %d: %v", op.EdgeID, err)
+		}
+	}
+	return nil
+}
+
+// invertOperation returns the logical inverse of op, for the WAL's UNDO
+// record. OpDeleteNode/OpDeleteEdge invert to an OpAddNode/OpAddEdge
+// carrying the deleted record's properties -- a durable WAL would also
+// need the original ID and (for edges) its endpoints to replay this
+// exactly, which a future on-disk log format should carry alongside it;
+// this in-memory stub only needs the inverse's shape recorded, not to
+// execute it.
+func invertOperation(op TransactionOperation) TransactionOperation {
+	switch op.Type {
+	case OpAddNode:
+		return TransactionOperation{Type: OpDeleteNode, NodeID: op.NodeID}
+	case OpAddEdge:
+		return TransactionOperation{Type: OpDeleteEdge, EdgeID: op.EdgeID}
+	case OpUpdateNode:
+		return TransactionOperation{Type: OpUpdateNode, NodeID: op.NodeID, Properties: op.Before, Before: op.Properties}
+	case OpUpdateEdge:
+		return TransactionOperation{Type: OpUpdateEdge, EdgeID: op.EdgeID, Properties: op.Before, Before: op.Properties}
+	case OpDeleteNode:
+		return TransactionOperation{Type: OpAddNode, NodeID: op.NodeID, Properties: op.Node.Properties}
+	case OpDeleteEdge:
+		return TransactionOperation{Type: OpAddEdge, EdgeID: op.EdgeID, Properties: op.Edge.Properties}
+	default:
+		return op
+	}
+}
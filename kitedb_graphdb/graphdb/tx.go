@@ -0,0 +1,260 @@
+package graphdb
+
+import (
+	"fmt"
+)
+
+// Tx is an explicit multi-statement transaction opened with GraphDB.Begin,
+// for REPL `BEGIN`/`COMMIT`/`ROLLBACK` blocks: statements run through it
+// share one transaction ID, so a failure partway through can be undone as
+// a unit instead of each statement committing independently. AddNode,
+// AddEdge, UpdateNode, UpdateEdge, DeleteNode, and DeleteEdge give callers
+// the same guarantee without going through Cypher, for programmatic
+// batches of graph mutations -- each call applies immediately against
+// GraphManager but only becomes durable on Commit, and Rollback undoes
+// everything recorded so far via the same TransactionOperation log
+// ExecuteQuery's Executor uses.
+type Tx struct {
+	db    *GraphDB
+	txnID int64
+	done  bool
+}
+
+// Begin starts a new transaction against db.
+func (db *GraphDB) Begin() (*Tx, error) {
+	return &Tx{
+		db:    db,
+		txnID: db.txnMgr.BeginTransaction(),
+	}, nil
+}
+
+// ExecuteQuery runs a Cypher statement inside the transaction.
+func (tx *Tx) ExecuteQuery(query string) ([]map[string]interface{}, error) {
+	if tx.done {
+		return nil, fmt.Errorf("transaction already committed or rolled back")
+	}
+
+	tokenizer := NewTokenizer(query)
+	tokens := tokenizer.Tokenize()
+	parser := NewParser(tokens)
+	ast, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.db.executor.Execute(tx.txnID, ast)
+}
+
+// Commit finalizes the transaction.
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return fmt.Errorf("transaction already committed or rolled back")
+	}
+	tx.done = true
+	return tx.db.txnMgr.CommitTransaction(tx.txnID)
+}
+
+// Rollback discards the transaction, undoing every operation it recorded.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return fmt.Errorf("transaction already committed or rolled back")
+	}
+	tx.done = true
+	return tx.db.txnMgr.RollbackTransaction(tx.txnID, tx.db.graph)
+}
+
+// Savepoint marks the current point in the transaction for a later
+// RollbackTo, without ending the transaction.
+func (tx *Tx) Savepoint() (int, error) {
+	if tx.done {
+		return 0, fmt.Errorf("transaction already committed or rolled back")
+	}
+	return tx.db.txnMgr.Savepoint(tx.txnID)
+}
+
+// RollbackTo undoes everything recorded since sp, leaving the transaction
+// open so further statements can still run against it.
+func (tx *Tx) RollbackTo(sp int) error {
+	if tx.done {
+		return fmt.Errorf("transaction already committed or rolled back")
+	}
+	return tx.db.txnMgr.RollbackTo(tx.txnID, sp, tx.db.graph)
+}
+
+// AddNode adds node within the transaction, recording it with the same
+// TransactionOperation Executor.executeCreate logs for a Cypher CREATE --
+// Rollback undoes it by deleting the node again (see undoOperation).
+func (tx *Tx) AddNode(node Node) (int64, error) {
+	if tx.done {
+		return 0, fmt.Errorf("transaction already committed or rolled back")
+	}
+	nodeID, err := tx.db.graph.AddNode(node)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:   OpAddNode,
+		NodeID: nodeID,
+	}); err != nil {
+		return 0, err
+	}
+	return nodeID, nil
+}
+
+// AddEdge adds edge within the transaction; see AddNode.
+func (tx *Tx) AddEdge(edge Edge) (int64, error) {
+	if tx.done {
+		return 0, fmt.Errorf("transaction already committed or rolled back")
+	}
+	edgeID, err := tx.db.graph.AddEdge(edge)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:   OpAddEdge,
+		EdgeID: edgeID,
+	}); err != nil {
+		return 0, err
+	}
+	return edgeID, nil
+}
+
+// UpdateNode merges properties into nodeID within the transaction,
+// snapshotting its prior properties first so Rollback can restore them
+// wholesale (see Executor.executeSet, which records updates the same
+// way).
+func (tx *Tx) UpdateNode(nodeID int64, properties []Property) error {
+	if tx.done {
+		return fmt.Errorf("transaction already committed or rolled back")
+	}
+	node, err := tx.db.graph.GetNode(nodeID)
+	if err != nil {
+		return err
+	}
+	before := node.Properties
+	if err := tx.db.graph.UpdateNode(nodeID, properties); err != nil {
+		return err
+	}
+	return tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:       OpUpdateNode,
+		NodeID:     nodeID,
+		Properties: properties,
+		Before:     before,
+	})
+}
+
+// UpdateEdge mirrors UpdateNode for edges.
+func (tx *Tx) UpdateEdge(edgeID int64, properties []Property) error {
+	if tx.done {
+		return fmt.Errorf("transaction already committed or rolled back")
+	}
+	edge, err := tx.db.graph.GetEdge(edgeID)
+	if err != nil {
+		return err
+	}
+	before := edge.Properties
+	if err := tx.db.graph.UpdateEdge(edgeID, properties); err != nil {
+		return err
+	}
+	return tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:       OpUpdateEdge,
+		EdgeID:     edgeID,
+		Properties: properties,
+		Before:     before,
+	})
+}
+
+// DeleteNode deletes nodeID within the transaction, snapshotting the full
+// record first so Rollback can recreate it (see Executor.executeDelete,
+// which records deletes the same way).
+func (tx *Tx) DeleteNode(nodeID int64) error {
+	if tx.done {
+		return fmt.Errorf("transaction already committed or rolled back")
+	}
+	node, err := tx.db.graph.GetNode(nodeID)
+	if err != nil {
+		return err
+	}
+	if err := tx.db.graph.DeleteNode(nodeID); err != nil {
+		return err
+	}
+	return tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:   OpDeleteNode,
+		NodeID: nodeID,
+		Node:   node,
+	})
+}
+
+// IncrementNodeWeight adds delta to nodeID's Weight within the
+// transaction, snapshotting the prior weight first so Rollback can
+// restore it (see GraphManager.IncrementNodeWeight and
+// TransactionOperation.BeforeWeight).
+func (tx *Tx) IncrementNodeWeight(nodeID int64, delta float64) (float64, error) {
+	if tx.done {
+		return 0, fmt.Errorf("transaction already committed or rolled back")
+	}
+	node, err := tx.db.graph.GetNode(nodeID)
+	if err != nil {
+		return 0, err
+	}
+	before := node.Weight
+	weight, err := tx.db.graph.IncrementNodeWeight(nodeID, delta)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:          OpUpdateNode,
+		NodeID:        nodeID,
+		Before:        node.Properties,
+		WeightChanged: true,
+		BeforeWeight:  before,
+	}); err != nil {
+		return 0, err
+	}
+	return weight, nil
+}
+
+// IncrementEdgeWeight mirrors IncrementNodeWeight for edges.
+func (tx *Tx) IncrementEdgeWeight(edgeID int64, delta float64) (float64, error) {
+	if tx.done {
+		return 0, fmt.Errorf("transaction already committed or rolled back")
+	}
+	edge, err := tx.db.graph.GetEdge(edgeID)
+	if err != nil {
+		return 0, err
+	}
+	before := edge.Weight
+	weight, err := tx.db.graph.IncrementEdgeWeight(edgeID, delta)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:          OpUpdateEdge,
+		EdgeID:        edgeID,
+		Before:        edge.Properties,
+		WeightChanged: true,
+		BeforeWeight:  before,
+	}); err != nil {
+		return 0, err
+	}
+	return weight, nil
+}
+
+// DeleteEdge mirrors DeleteNode for edges.
+func (tx *Tx) DeleteEdge(edgeID int64) error {
+	if tx.done {
+		return fmt.Errorf("transaction already committed or rolled back")
+	}
+	edge, err := tx.db.graph.GetEdge(edgeID)
+	if err != nil {
+		return err
+	}
+	if err := tx.db.graph.DeleteEdge(edgeID); err != nil {
+		return err
+	}
+	return tx.db.txnMgr.RecordOperation(tx.txnID, TransactionOperation{
+		Type:   OpDeleteEdge,
+		EdgeID: edgeID,
+		Edge:   edge,
+	})
+}
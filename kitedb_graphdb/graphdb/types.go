@@ -1,5 +1,7 @@
 package graphdb
 
+import "time"
+
 // PropertyType defines supported property types
 type PropertyType int
 
@@ -7,6 +9,23 @@ const (
 	PropertyInt PropertyType = iota
 	PropertyString
 	PropertyBool
+	PropertyFloat
+	// PropertyTimestamp holds a time.Time, serialized as int64 unix nanos
+	// (see writePropertyV2 in codec_v2.go and readProperty in utils.go).
+	PropertyTimestamp
+	// PropertyBytes holds a raw []byte blob, length-prefixed on disk.
+	PropertyBytes
+	// PropertyList holds a []interface{} whose elements are themselves
+	// any of these PropertyTypes (including nested lists/maps), each
+	// tagged with its own type byte so the list can be heterogeneous.
+	PropertyList
+	// PropertyMap holds a map[string]interface{} whose values are
+	// themselves any of these PropertyTypes, each tagged the same way
+	// PropertyList's elements are.
+	PropertyMap
+	// PropertyNull marks an absent or typed-nil value, so a Property can
+	// round-trip through Serialize/Deserialize without a Go value at all.
+	PropertyNull
 )
 
 // Property represents a key-value pair
@@ -16,12 +35,68 @@ type Property struct {
 	Type  PropertyType
 }
 
+// NewIntProperty builds an int64-valued Property.
+func NewIntProperty(key string, value int64) Property {
+	return Property{Key: key, Value: value, Type: PropertyInt}
+}
+
+// NewStringProperty builds a string-valued Property.
+func NewStringProperty(key string, value string) Property {
+	return Property{Key: key, Value: value, Type: PropertyString}
+}
+
+// NewBoolProperty builds a bool-valued Property.
+func NewBoolProperty(key string, value bool) Property {
+	return Property{Key: key, Value: value, Type: PropertyBool}
+}
+
+// NewFloatProperty builds a float64-valued Property.
+func NewFloatProperty(key string, value float64) Property {
+	return Property{Key: key, Value: value, Type: PropertyFloat}
+}
+
+// NewTimestampProperty builds a time.Time-valued Property, stored on disk
+// as int64 unix nanos.
+func NewTimestampProperty(key string, value time.Time) Property {
+	return Property{Key: key, Value: value, Type: PropertyTimestamp}
+}
+
+// NewBytesProperty builds a []byte-valued Property.
+func NewBytesProperty(key string, value []byte) Property {
+	return Property{Key: key, Value: value, Type: PropertyBytes}
+}
+
+// NewListProperty builds a Property holding a list of values, each of
+// which must itself be a Go value one of the PropertyType constructors
+// would accept (int64, string, bool, float64, time.Time, []byte, a
+// []interface{} list, or a map[string]interface{} map).
+func NewListProperty(key string, values []interface{}) Property {
+	return Property{Key: key, Value: values, Type: PropertyList}
+}
+
+// NewMapProperty builds a Property holding a string-keyed map of values,
+// each of which must itself be a Go value one of the PropertyType
+// constructors would accept.
+func NewMapProperty(key string, values map[string]interface{}) Property {
+	return Property{Key: key, Value: values, Type: PropertyMap}
+}
+
+// NewNullProperty builds a Property with no value.
+func NewNullProperty(key string) Property {
+	return Property{Key: key, Value: nil, Type: PropertyNull}
+}
+
 // Node represents a graph node
 type Node struct {
 	ID         int64
 	Labels     []string
 	Properties []Property
 	Active     bool
+	// Weight is a numeric score separate from Properties, updated
+	// atomically by GraphManager.IncrementNodeWeight rather than through
+	// the ordinary merge-by-key property update -- useful for e.g. a
+	// PageRank-style score many concurrent edges/queries adjust.
+	Weight float64
 }
 
 // Edge represents a graph edge
@@ -32,6 +107,44 @@ type Edge struct {
 	Target     int64
 	Properties []Property
 	Active     bool
+	Cascade    CascadeSpec
+	// Weight mirrors Node.Weight; see GraphManager.IncrementEdgeWeight and
+	// UpsertEdgeWeight.
+	Weight float64
+	// Ordinal distinguishes parallel edges of the same Type between the
+	// same Source/Target pair (Kythe calls this a ParseOrdinal), e.g. the
+	// Nth argument edge out of a call-site node. UnspecifiedOrdinal means
+	// GraphManager.AddEdge should auto-assign the next free ordinal.
+	Ordinal int32
+	// End1Role/End2Role name what Source/Target play in this relationship
+	// (EliasDB calls these an edge's "end roles", e.g. "author"/"book" for
+	// an AUTHORED_BY edge), and End1Cascading/End2Cascading say whether
+	// deleting that end should be treated as cascading the other end's
+	// deletion along with it. GraphManager.TraverseNode's spec string
+	// matches against End1Role/End2Role; the Cascading flags are metadata
+	// only -- the cascade behavior itself is still driven by Cascade
+	// (CascadeSpec), since that's what DeleteNodeCascade already reads.
+	End1Role      string
+	End1Cascading bool
+	End2Role      string
+	End2Cascading bool
+}
+
+// UnspecifiedOrdinal marks an Edge whose Ordinal was not given explicitly
+// in CREATE syntax, telling GraphManager.AddEdge to auto-assign the next
+// free one among edges sharing its Type, Source and Target.
+const UnspecifiedOrdinal int32 = -1
+
+// CascadeSpec describes how deleting one endpoint of an edge should pull
+// the other endpoint along with it, EliasDB-style. The four flags are
+// independent: a "last" flag only fires once the edge being removed is
+// the last active one keeping the other endpoint reachable through this
+// relationship.
+type CascadeSpec struct {
+	CascadeToTarget       bool // deleting Source always deletes Target too
+	CascadeLastToTarget   bool // deleting Source deletes Target once this is Target's last incoming edge
+	CascadeFromTarget     bool // deleting Target always deletes Source too
+	CascadeLastFromTarget bool // deleting Target deletes Source once this is Source's last outgoing edge
 }
 
 // ASTNodeType defines types for AST nodes
@@ -53,7 +166,70 @@ const (
 	NodeIdentifier
 	NodeProperty
 	NodeLiteral
-	NodeExpression
+	NodeDirection
+	NodeVarLength
+	NodeOrdinal
+	// NodeBinaryOp and NodeUnaryOp cover the whole WHERE grammar: boolean
+	// combinators (AND/OR, NOT), comparisons (=, <>, <, <=, >, >=, =~,
+	// IN), IS [NOT] NULL, and the string predicates (STARTS WITH, ENDS
+	// WITH, CONTAINS). The operator lives in Value; NodeBinaryOp has two
+	// Children (left, right), NodeUnaryOp has one.
+	NodeBinaryOp
+	NodeUnaryOp
+	// NodeList is a literal list (`[1, 2, 3]`), used both as IN's
+	// right-hand side and as a standalone primary expression.
+	NodeList
+)
+
+// Path is a single enumerated hop-chain produced by a variable-length
+// relationship match, e.g. `p = (a)-[:FRIEND*1..3]->(b)`. NodeIDs has one
+// more entry than EdgeIDs: NodeIDs[0] is the starting node and
+// EdgeIDs[i] connects NodeIDs[i] to NodeIDs[i+1].
+type Path struct {
+	NodeIDs []int64
+	EdgeIDs []int64
+}
+
+// Operators recognized by NodeBinaryOp/NodeUnaryOp.Value in a WHERE
+// clause. AND/OR/comparisons/IN/string predicates are NodeBinaryOp (their
+// right-hand operand is the second child); NOT and IS [NOT] NULL are
+// NodeUnaryOp (their single operand is the only child).
+const (
+	OpAnd          = "AND"
+	OpOr           = "OR"
+	OpNot          = "NOT"
+	OpEqual        = "="
+	OpNotEqual     = "<>"
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpRegexMatch   = "=~"
+	OpIn           = "IN"
+	OpIsNull       = "ISNULL"
+	OpIsNotNull    = "ISNOTNULL"
+	OpStartsWith   = "STARTSWITH"
+	OpEndsWith     = "ENDSWITH"
+	OpContains     = "CONTAINS"
+	// OpFullText is FULLTEXT(var.key, "query") -- see fulltext.go for the
+	// query syntax. Like OpStartsWith et al. it's a NodeBinaryOp whose
+	// second child is a string NodeLiteral, but its first child comes from
+	// propertyAccess via an explicit function-call form rather than
+	// comparisonExpr's `var.key OP ...` shape.
+	OpFullText = "FULLTEXT"
+	// OpAdd is SET's one arithmetic RHS form, `var.key = var.key + N` --
+	// see Parser.selfIncrementAssignment and Executor.executeSet's
+	// NodeBinaryOp branch.
+	OpAdd = "+"
+)
+
+// Relationship directions recognized in MATCH/CREATE patterns:
+// "-[r:TYPE]->" (DirectionForward), "<-[r:TYPE]-" (DirectionReverse), and
+// "-[r:TYPE]-" (DirectionEither, no constraint on which end is which).
+const (
+	DirectionForward = "forward"
+	DirectionReverse = "reverse"
+	DirectionEither  = "either"
 )
 
 // ASTNode represents a node in the Abstract Syntax Tree
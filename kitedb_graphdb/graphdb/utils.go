@@ -4,106 +4,38 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"time"
 )
 
-// Serialize converts a Node or Edge to a byte slice
+// Serialize converts a Node or Edge to a byte slice, using the version=2
+// TLV layout (see codec_v2.go). It is a thin wrapper around SerializeTo
+// for callers that want the whole encoding as a []byte.
 func Serialize(v interface{}) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.Grow(64) // Pre-allocate for efficiency
-
-	// Write version (1 byte)
-	if err := buf.WriteByte(1); err != nil {
-		return nil, fmt.Errorf("failed to write version: %v", err)
-	}
-
-	switch val := v.(type) {
-	case Node:
-		// Write ID (8 bytes)
-		if err := binary.Write(&buf, binary.LittleEndian, val.ID); err != nil {
-			return nil, fmt.Errorf("failed to write node ID: %v", err)
-		}
-		// Write Active (1 byte)
-		if err := binary.Write(&buf, binary.LittleEndian, btoi(val.Active)); err != nil {
-			return nil, fmt.Errorf("failed to write node active flag: %v", err)
-		}
-		// Write number of labels (4 bytes)
-		if err := binary.Write(&buf, binary.LittleEndian, uint32(len(val.Labels))); err != nil {
-			return nil, fmt.Errorf("failed to write label count: %v", err)
-		}
-		// Write labels
-		for _, label := range val.Labels {
-			if err := binary.Write(&buf, binary.LittleEndian, uint32(len(label))); err != nil {
-				return nil, fmt.Errorf("failed to write label length for %q: %v", label, err)
-			}
-			if _, err := buf.WriteString(label); err != nil {
-				return nil, fmt.Errorf("failed to write label %q: %v", label, err)
-			}
-		}
-		// Write number of properties (4 bytes)
-		if err := binary.Write(&buf, binary.LittleEndian, uint32(len(val.Properties))); err != nil {
-			return nil, fmt.Errorf("failed to write property count: %v", err)
-		}
-		// Write properties
-		for _, prop := range val.Properties {
-			if err := writeProperty(&buf, prop); err != nil {
-				return nil, fmt.Errorf("failed to serialize property %q: %v", prop.Key, err)
-			}
-		}
-	case Edge:
-		// Write ID (8 bytes)
-		if err := binary.Write(&buf, binary.LittleEndian, val.ID); err != nil {
-			return nil, fmt.Errorf("failed to write edge ID: %v", err)
-		}
-		// Write Active (1 byte)
-		if err := binary.Write(&buf, binary.LittleEndian, btoi(val.Active)); err != nil {
-			return nil, fmt.Errorf("failed to write edge active flag: %v", err)
-		}
-		// Write Type length and value
-		if err := binary.Write(&buf, binary.LittleEndian, uint32(len(val.Type))); err != nil {
-			return nil, fmt.Errorf("failed to write type length: %v", err)
-		}
-		if _, err := buf.WriteString(val.Type); err != nil {
-			return nil, fmt.Errorf("failed to write edge type %q: %v", val.Type, err)
-		}
-		// Write Source and Target (8 bytes each)
-		if err := binary.Write(&buf, binary.LittleEndian, val.Source); err != nil {
-			return nil, fmt.Errorf("failed to write source ID: %v", err)
-		}
-		if err := binary.Write(&buf, binary.LittleEndian, val.Target); err != nil {
-			return nil, fmt.Errorf("failed to write target ID: %v", err)
-		}
-		// Write number of properties (4 bytes)
-		if err := binary.Write(&buf, binary.LittleEndian, uint32(len(val.Properties))); err != nil {
-			return nil, fmt.Errorf("failed to write property count: %v", err)
-		}
-		// Write properties
-		for _, prop := range val.Properties {
-			if err := writeProperty(&buf, prop); err != nil {
-				return nil, fmt.Errorf("failed to serialize property %q: %v", prop.Key, err)
-			}
-		}
-	default:
-		return nil, fmt.Errorf("unsupported type for serialization: %T", v)
+	if err := SerializeTo(&buf, v); err != nil {
+		return nil, err
 	}
 	return buf.Bytes(), nil
 }
 
-// Deserialize converts a byte slice to a Node or Edge
+// Deserialize converts a byte slice back to a Node or Edge, reading
+// whichever version it was written with -- version=1's fixed positional
+// layout (kept read-only for files written before codec_v2.go) or
+// version=2's TLV layout. It is a thin wrapper around DeserializeFrom.
 func Deserialize(data []byte, v interface{}) error {
-	buf := bytes.NewReader(data)
 	if len(data) == 0 {
 		return fmt.Errorf("empty data for deserialization")
 	}
+	return DeserializeFrom(bytes.NewReader(data), v)
+}
 
-	// Read version
-	var version byte
-	if err := binary.Read(buf, binary.LittleEndian, &version); err != nil {
-		return fmt.Errorf("failed to read version: %v", err)
-	}
-	if version != 1 {
-		return fmt.Errorf("unsupported version: %d", version)
-	}
-
+// deserializeV1Body decodes the version=1 positional layout, buf
+// positioned just after the version byte. It is the Deserialize from
+// before version=2 existed, read-only, except that an Edge is read with a
+// cascade-flags byte and an int32 Ordinal right after Target; a version=1
+// Edge written without those two fields does not decode correctly.
+func deserializeV1Body(buf *bytes.Reader, v interface{}) error {
 	switch val := v.(type) {
 	case *Node:
 		// Read ID
@@ -187,6 +119,18 @@ func Deserialize(data []byte, v interface{}) error {
 		}
 		val.Source = source
 		val.Target = target
+		// Read cascade flags
+		var cascadeByte byte
+		if err := binary.Read(buf, binary.LittleEndian, &cascadeByte); err != nil {
+			return fmt.Errorf("failed to read cascade flags: %v", err)
+		}
+		val.Cascade = cascadeSpecFromByte(cascadeByte)
+		// Read Ordinal
+		var ordinal int32
+		if err := binary.Read(buf, binary.LittleEndian, &ordinal); err != nil {
+			return fmt.Errorf("failed to read ordinal: %v", err)
+		}
+		val.Ordinal = ordinal
 		// Read number of properties
 		var propCount uint32
 		if err := binary.Read(buf, binary.LittleEndian, &propCount); err != nil {
@@ -205,49 +149,32 @@ func Deserialize(data []byte, v interface{}) error {
 	return nil
 }
 
-// writeProperty serializes a single property
-func writeProperty(buf *bytes.Buffer, prop Property) error {
-	if err := binary.Write(buf, binary.LittleEndian, uint32(len(prop.Key))); err != nil {
-		return fmt.Errorf("failed to write key length: %v", err)
-	}
-	if _, err := buf.WriteString(prop.Key); err != nil {
-		return fmt.Errorf("failed to write key: %v", err)
-	}
-	if err := binary.Write(buf, binary.LittleEndian, byte(prop.Type)); err != nil {
-		return fmt.Errorf("failed to write type: %v", err)
-	}
-	switch prop.Type {
-	case PropertyInt:
-		if v, ok := prop.Value.(int64); ok {
-			if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
-				return fmt.Errorf("failed to write int64 value: %v", err)
-			}
-		} else {
-			return fmt.Errorf("invalid int64 value for property %q: %T", prop.Key, prop.Value)
-		}
-	case PropertyString:
-		if v, ok := prop.Value.(string); ok {
-			if err := binary.Write(buf, binary.LittleEndian, uint32(len(v))); err != nil {
-				return fmt.Errorf("failed to write string length: %v", err)
-			}
-			if _, err := buf.WriteString(v); err != nil {
-				return fmt.Errorf("failed to write string value: %v", err)
-			}
-		} else {
-			return fmt.Errorf("invalid string value for property %q: %T", prop.Key, prop.Value)
-		}
-	case PropertyBool:
-		if v, ok := prop.Value.(bool); ok {
-			if err := binary.Write(buf, binary.LittleEndian, btoi(v)); err != nil {
-				return fmt.Errorf("failed to write bool value: %v", err)
-			}
-		} else {
-			return fmt.Errorf("invalid bool value for property %q: %T", prop.Key, prop.Value)
-		}
+// inferPropertyType maps a bare Go value (as found inside a PropertyList
+// or PropertyMap) to the PropertyType it should be tagged with -- used by
+// version=2's writeTaggedValueV2 (see codec_v2.go).
+func inferPropertyType(value interface{}) (PropertyType, error) {
+	switch value.(type) {
+	case nil:
+		return PropertyNull, nil
+	case int64:
+		return PropertyInt, nil
+	case string:
+		return PropertyString, nil
+	case bool:
+		return PropertyBool, nil
+	case float64:
+		return PropertyFloat, nil
+	case time.Time:
+		return PropertyTimestamp, nil
+	case []byte:
+		return PropertyBytes, nil
+	case []interface{}:
+		return PropertyList, nil
+	case map[string]interface{}:
+		return PropertyMap, nil
 	default:
-		return fmt.Errorf("unsupported property type %d for property %q", prop.Type, prop.Key)
+		return PropertyNull, fmt.Errorf("unsupported value type: %T", value)
 	}
-	return nil
 }
 
 // readProperty deserializes a single property
@@ -270,39 +197,159 @@ func readProperty(buf *bytes.Reader, prop *Property) error {
 	}
 	prop.Type = PropertyType(propType)
 
-	var value interface{}
-	switch prop.Type {
+	value, err := readValue(buf, prop.Type)
+	if err != nil {
+		return fmt.Errorf("failed to read value for property %q: %v", keyBytes, err)
+	}
+	prop.Key = string(keyBytes)
+	prop.Value = value
+	return nil
+}
+
+// readValue decodes version=1's encoding of propType's value, with no key or
+// type tag of its own -- readProperty reads the tag once up front, and
+// readTaggedValue reads a PropertyList/PropertyMap entry's own tag before
+// calling this.
+func readValue(buf *bytes.Reader, propType PropertyType) (interface{}, error) {
+	switch propType {
 	case PropertyInt:
 		var v int64
 		if err := binary.Read(buf, binary.LittleEndian, &v); err != nil {
-			return fmt.Errorf("failed to read int64 value: %v", err)
+			return nil, fmt.Errorf("failed to read int64 value: %v", err)
 		}
-		value = v
+		return v, nil
 	case PropertyString:
 		var lenValue uint32
 		if err := binary.Read(buf, binary.LittleEndian, &lenValue); err != nil {
-			return fmt.Errorf("failed to read string length: %v", err)
+			return nil, fmt.Errorf("failed to read string length: %v", err)
 		}
 		if int(lenValue) > buf.Len() {
-			return fmt.Errorf("string length %d exceeds remaining buffer %d", lenValue, buf.Len())
+			return nil, fmt.Errorf("string length %d exceeds remaining buffer %d", lenValue, buf.Len())
 		}
 		valueBytes := make([]byte, lenValue)
 		if _, err := buf.Read(valueBytes); err != nil {
-			return fmt.Errorf("failed to read string value: %v", err)
+			return nil, fmt.Errorf("failed to read string value: %v", err)
 		}
-		value = string(valueBytes)
+		return string(valueBytes), nil
 	case PropertyBool:
 		var v byte
 		if err := binary.Read(buf, binary.LittleEndian, &v); err != nil {
-			return fmt.Errorf("failed to read bool value: %v", err)
+			return nil, fmt.Errorf("failed to read bool value: %v", err)
+		}
+		return v != 0, nil
+	case PropertyFloat:
+		var v float64
+		if err := binary.Read(buf, binary.LittleEndian, &v); err != nil {
+			return nil, fmt.Errorf("failed to read float64 value: %v", err)
 		}
-		value = v != 0
+		return v, nil
+	case PropertyTimestamp:
+		var nanos int64
+		if err := binary.Read(buf, binary.LittleEndian, &nanos); err != nil {
+			return nil, fmt.Errorf("failed to read timestamp value: %v", err)
+		}
+		return time.Unix(0, nanos).UTC(), nil
+	case PropertyBytes:
+		var lenValue uint32
+		if err := binary.Read(buf, binary.LittleEndian, &lenValue); err != nil {
+			return nil, fmt.Errorf("failed to read bytes length: %v", err)
+		}
+		if int(lenValue) > buf.Len() {
+			return nil, fmt.Errorf("bytes length %d exceeds remaining buffer %d", lenValue, buf.Len())
+		}
+		valueBytes := make([]byte, lenValue)
+		if _, err := buf.Read(valueBytes); err != nil {
+			return nil, fmt.Errorf("failed to read bytes value: %v", err)
+		}
+		return valueBytes, nil
+	case PropertyList:
+		var count uint32
+		if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+			return nil, fmt.Errorf("failed to read list count: %v", err)
+		}
+		if int(count) > buf.Len() {
+			return nil, fmt.Errorf("list count %d exceeds remaining buffer %d", count, buf.Len())
+		}
+		elems := make([]interface{}, count)
+		for i := uint32(0); i < count; i++ {
+			elem, err := readTaggedValue(buf)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read list element %d: %v", i, err)
+			}
+			elems[i] = elem
+		}
+		return elems, nil
+	case PropertyMap:
+		var count uint32
+		if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+			return nil, fmt.Errorf("failed to read map count: %v", err)
+		}
+		if int(count) > buf.Len() {
+			return nil, fmt.Errorf("map count %d exceeds remaining buffer %d", count, buf.Len())
+		}
+		values := make(map[string]interface{}, count)
+		for i := uint32(0); i < count; i++ {
+			var lenKey uint32
+			if err := binary.Read(buf, binary.LittleEndian, &lenKey); err != nil {
+				return nil, fmt.Errorf("failed to read map key length: %v", err)
+			}
+			if int(lenKey) > buf.Len() {
+				return nil, fmt.Errorf("map key length %d exceeds remaining buffer %d", lenKey, buf.Len())
+			}
+			keyBytes := make([]byte, lenKey)
+			if _, err := buf.Read(keyBytes); err != nil {
+				return nil, fmt.Errorf("failed to read map key: %v", err)
+			}
+			val, err := readTaggedValue(buf)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read map value for key %q: %v", keyBytes, err)
+			}
+			values[string(keyBytes)] = val
+		}
+		return values, nil
+	case PropertyNull:
+		return nil, nil
 	default:
-		return fmt.Errorf("unsupported property type %d", prop.Type)
+		return nil, fmt.Errorf("unsupported property type %d", propType)
+	}
+}
+
+// readTaggedValue decodes a version=1 tagged value: a type byte followed by
+// readValue's decoding for it.
+func readTaggedValue(buf *bytes.Reader) (interface{}, error) {
+	var elemType byte
+	if err := binary.Read(buf, binary.LittleEndian, &elemType); err != nil {
+		return nil, fmt.Errorf("failed to read element type: %v", err)
+	}
+	return readValue(buf, PropertyType(elemType))
+}
+
+// cascadeSpecToByte packs a CascadeSpec's four flags into one byte.
+func cascadeSpecToByte(c CascadeSpec) byte {
+	var b byte
+	if c.CascadeToTarget {
+		b |= 1 << 0
+	}
+	if c.CascadeLastToTarget {
+		b |= 1 << 1
+	}
+	if c.CascadeFromTarget {
+		b |= 1 << 2
+	}
+	if c.CascadeLastFromTarget {
+		b |= 1 << 3
+	}
+	return b
+}
+
+// cascadeSpecFromByte unpacks a byte written by cascadeSpecToByte.
+func cascadeSpecFromByte(b byte) CascadeSpec {
+	return CascadeSpec{
+		CascadeToTarget:       b&(1<<0) != 0,
+		CascadeLastToTarget:   b&(1<<1) != 0,
+		CascadeFromTarget:     b&(1<<2) != 0,
+		CascadeLastFromTarget: b&(1<<3) != 0,
 	}
-	prop.Key = string(keyBytes)
-	prop.Value = value
-	return nil
 }
 
 // btoi converts bool to byte (0 or 1)
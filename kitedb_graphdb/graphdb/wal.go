@@ -1,31 +1,389 @@
 package graphdb
 
-// WALManager is a minimal stub for write-ahead logging
+import (
+	"fmt"
+	"os"
+)
+
+// WALRecordKind distinguishes a redo entry (the operation as it was
+// applied) from its paired undo entry (the operation that would reverse
+// it) in WALManager's in-memory records. TransactionManager's
+// RollbackTransaction/RollbackTo do not read these entries; they unwind
+// their own per-transaction operation list through undoOperation. This is
+// separate from the durable on-disk log below, which drives Recover after
+// a restart.
+type WALRecordKind string
+
+const (
+	WALRedo WALRecordKind = "REDO"
+	WALUndo WALRecordKind = "UNDO"
+)
+
+// WALRecord is a single entry in the in-memory undo log.
+type WALRecord struct {
+	Kind  WALRecordKind
+	TxnID int64
+	Op    TransactionOperation
+}
+
+// WALManager is a durable write-ahead log. Every Insert/Update/Delete a
+// transaction performs is appended as a CRC32-checked frame to an
+// append-only log file (see wal_log.go for the on-disk format), and
+// Commit fsyncs a COMMIT frame before returning.
+//
+// GraphManager's AddNode/UpdateNode/DeleteNode write through BufferPool
+// immediately (see bufferpool.go), ahead of the WAL record being written
+// here -- RecordOperation calls LogOperation only after the data write
+// already succeeded. So unlike a classic WAL, this log isn't what makes a
+// committed write durable; what it protects against is the other half of
+// the problem, a transaction whose data landed on disk but whose commit
+// never did. Recover below undoes exactly that case at startup.
+//
+// records/indexDefs are the in-memory bookkeeping the original stub kept,
+// and LogOperation keeps maintaining records alongside writing a durable
+// frame. Checkpoint reads records to tell which transactions are still
+// open; TransactionManager's rollback paths do not read it.
 type WALManager struct {
-	operations []TransactionOperation // In-memory operation log
+	file    *os.File
+	path    string
+	nextLSN uint64
+
+	records []WALRecord
+
+	// indexDefs holds every CreateIndex definition logged so far, so
+	// RebuildIndexes can recreate them without the caller re-issuing each
+	// CreateIndex by hand. Unlike records, which Commit clears per
+	// transaction, index definitions are schema, not transactional state,
+	// so Commit never touches this slice. It isn't part of the durable
+	// frame log, so it doesn't survive a restart on its own -- see
+	// GraphDB.RebuildIndexes.
+	indexDefs []IndexDef
+
+	// fulltextDefs mirrors indexDefs for CreateFullTextIndex definitions;
+	// see LogFullTextIndexDefinition.
+	fulltextDefs []FullTextIndexDef
 }
 
-// NewWALManager initializes a new WALManager
-func NewWALManager() *WALManager {
+// NewWALManager opens (or creates) the durable log file at path.
+func NewWALManager(path string) (*WALManager, error) {
+	file, err := openWALFile(path)
+	if err != nil {
+		return nil, err
+	}
+	nextLSN, err := scanNextLSN(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to scan WAL file: %v", err)
+	}
 	return &WALManager{
-		operations: []TransactionOperation{},
+		file:    file,
+		path:    path,
+		nextLSN: nextLSN,
+		records: []WALRecord{},
+	}, nil
+}
+
+// appendFrame writes a frame under the next LSN and advances it.
+func (wm *WALManager) appendFrame(txnID int64, recType walRecType, payload []byte) error {
+	frame := walFrame{LSN: wm.nextLSN, TxnID: txnID, Type: recType, Payload: payload}
+	if err := writeWALFrame(wm.file, frame); err != nil {
+		return err
 	}
+	wm.nextLSN++
+	return nil
+}
+
+// Begin marks txnID as started in the durable log. It's best-effort --
+// LogOperation/Commit never depend on a Begin frame actually existing, so
+// a failure here doesn't stop the transaction from proceeding in memory.
+func (wm *WALManager) Begin(txnID int64) error {
+	return wm.appendFrame(txnID, walRecBegin, nil)
 }
 
-// LogOperation logs a transaction operation
-func (wm *WALManager) LogOperation(op TransactionOperation) error {
-	wm.operations = append(wm.operations, op)
+// LogInsert durably records the after-image of a newly inserted node
+// (isNode true) or edge, serialized with the same Serialize helper
+// RecordManager uses to encode it to disk (see codec.go's CompactCodec).
+func (wm *WALManager) LogInsert(txnID int64, isNode bool, id int64, after interface{}) error {
+	afterBytes, err := Serialize(after)
+	if err != nil {
+		return fmt.Errorf("failed to serialize WAL insert image: %v", err)
+	}
+	return wm.appendFrame(txnID, walRecInsert, encodeWALPayload(isNode, id, nil, afterBytes))
+}
+
+// LogUpdate durably records both the before- and after-images of an
+// updated node or edge, so Recover can undo an uncommitted update by
+// replaying the before-image back.
+func (wm *WALManager) LogUpdate(txnID int64, isNode bool, id int64, before, after interface{}) error {
+	beforeBytes, err := Serialize(before)
+	if err != nil {
+		return fmt.Errorf("failed to serialize WAL update before-image: %v", err)
+	}
+	afterBytes, err := Serialize(after)
+	if err != nil {
+		return fmt.Errorf("failed to serialize WAL update after-image: %v", err)
+	}
+	return wm.appendFrame(txnID, walRecUpdate, encodeWALPayload(isNode, id, beforeBytes, afterBytes))
+}
+
+// LogDelete durably records the before-image of a deleted node or edge,
+// so Recover can undo an uncommitted delete by re-inserting it.
+func (wm *WALManager) LogDelete(txnID int64, isNode bool, id int64, before interface{}) error {
+	beforeBytes, err := Serialize(before)
+	if err != nil {
+		return fmt.Errorf("failed to serialize WAL delete before-image: %v", err)
+	}
+	return wm.appendFrame(txnID, walRecDelete, encodeWALPayload(isNode, id, beforeBytes, nil))
+}
+
+// LogOperation appends op's REDO record plus an UNDO record carrying its
+// inverse (see invertOperation) to the in-memory undo log, exactly as
+// before, and additionally durably logs op via LogInsert/LogUpdate/
+// LogDelete when it carries a full record to serialize. A few
+// RecordOperation call sites (bulk import in interchange.go, notably) only
+// thread an ID through an OpAddNode/OpAddEdge operation without the
+// record itself; those still get their in-memory REDO/UNDO entry for
+// rollback, just no durable image for Recover to replay.
+func (wm *WALManager) LogOperation(txnID int64, op TransactionOperation) error {
+	wm.records = append(wm.records, WALRecord{Kind: WALRedo, TxnID: txnID, Op: op})
+	wm.records = append(wm.records, WALRecord{Kind: WALUndo, TxnID: txnID, Op: invertOperation(op)})
+
+	switch op.Type {
+	case OpAddNode:
+		if op.Node.ID == 0 {
+			return nil
+		}
+		return wm.LogInsert(txnID, true, op.Node.ID, op.Node)
+	case OpAddEdge:
+		if op.Edge.ID == 0 {
+			return nil
+		}
+		return wm.LogInsert(txnID, false, op.Edge.ID, op.Edge)
+	case OpUpdateNode:
+		return wm.LogUpdate(txnID, true, op.NodeID, Node{Properties: op.Before}, Node{Properties: op.Properties})
+	case OpUpdateEdge:
+		return wm.LogUpdate(txnID, false, op.EdgeID, Edge{Properties: op.Before}, Edge{Properties: op.Properties})
+	case OpDeleteNode:
+		return wm.LogDelete(txnID, true, op.NodeID, op.Node)
+	case OpDeleteEdge:
+		return wm.LogDelete(txnID, false, op.EdgeID, op.Edge)
+	}
 	return nil
 }
 
-// Commit clears logged operations for a transaction
+// LogIndexDefinition records def so RebuildIndexes can recreate it later.
+func (wm *WALManager) LogIndexDefinition(def IndexDef) {
+	wm.indexDefs = append(wm.indexDefs, def)
+}
+
+// IndexDefinitions returns every index definition logged so far.
+func (wm *WALManager) IndexDefinitions() []IndexDef {
+	return append([]IndexDef{}, wm.indexDefs...)
+}
+
+// LogFullTextIndexDefinition records def so RebuildIndexes can recreate
+// it later. See LogIndexDefinition.
+func (wm *WALManager) LogFullTextIndexDefinition(def FullTextIndexDef) {
+	wm.fulltextDefs = append(wm.fulltextDefs, def)
+}
+
+// FullTextIndexDefinitions returns every full-text index definition
+// logged so far.
+func (wm *WALManager) FullTextIndexDefinitions() []FullTextIndexDef {
+	return append([]FullTextIndexDef{}, wm.fulltextDefs...)
+}
+
+// Commit writes a durable COMMIT frame and fsyncs the log before clearing
+// txnID's in-memory records, so a process that crashes any time after
+// Commit returns never mistakes txnID for one Recover needs to undo.
 func (wm *WALManager) Commit(txnID int64) error {
-	wm.operations = []TransactionOperation{}
+	if err := wm.appendFrame(txnID, walRecCommit, nil); err != nil {
+		return fmt.Errorf("failed to write WAL commit frame: %v", err)
+	}
+	if err := wm.file.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync WAL commit: %v", err)
+	}
+
+	var kept []WALRecord
+	for _, r := range wm.records {
+		if r.TxnID != txnID {
+			kept = append(kept, r)
+		}
+	}
+	wm.records = kept
 	return nil
 }
 
-// Close performs cleanup
-func (wm *WALManager) Close() error {
-	wm.operations = []TransactionOperation{}
+// Checkpoint bounds how much of the log Recover would ever need to
+// replay: it rewrites the log file to keep only frames belonging to
+// transactions still open in wm.records -- Commit removes a transaction's
+// entries from records, so anything left there is exactly what hasn't
+// committed yet -- discarding everything else.
+//
+// bp's dirty frames are flushed first -- BufferPool.WritePage now defers
+// the actual disk write (see bufferpool.go), so a page logged as part of
+// a committed transaction may still only exist in the pool's cache; this
+// checkpoint would otherwise discard the only WAL frames that could
+// reconstruct it.
+func (wm *WALManager) Checkpoint(bp *BufferPool) error {
+	if err := bp.FlushAll(); err != nil {
+		return fmt.Errorf("failed to flush buffer pool before checkpoint: %v", err)
+	}
+
+	open := make(map[int64]bool, len(wm.records))
+	for _, r := range wm.records {
+		open[r.TxnID] = true
+	}
+
+	frames, err := readAllWALFrames(wm.path)
+	if err != nil {
+		return fmt.Errorf("failed to read WAL for checkpoint: %v", err)
+	}
+
+	tmpPath := wm.path + ".checkpoint"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint file: %v", err)
+	}
+	if err := writeWALHeader(tmpFile); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	for _, frame := range frames {
+		if !open[frame.TxnID] {
+			continue
+		}
+		if err := writeWALFrame(tmpFile, frame); err != nil {
+			tmpFile.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync checkpoint file: %v", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close checkpoint file: %v", err)
+	}
+
+	if err := wm.file.Close(); err != nil {
+		return fmt.Errorf("failed to close old WAL file: %v", err)
+	}
+	if err := os.Rename(tmpPath, wm.path); err != nil {
+		return fmt.Errorf("failed to install checkpointed WAL file: %v", err)
+	}
+	file, err := os.OpenFile(wm.path, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen WAL file after checkpoint: %v", err)
+	}
+	wm.file = file
 	return nil
 }
+
+// Recover replays the durable log at startup: any transaction whose
+// frames never reached a COMMIT is undone against graph, using the same
+// ReplaceNodeProperties/RestoreNode/RestoreEdge/DeleteNode/DeleteEdge
+// helpers TransactionManager's undoOperation uses for an in-process
+// rollback. It's safe to call unconditionally, even after a clean
+// shutdown, since a committed transaction's frames are simply skipped.
+//
+// The request that prompted this method described it as
+// Recover(bp *BufferPool, sm *StorageManager); undoing an operation means
+// calling back into GraphManager (ReplaceNodeProperties, RestoreNode,
+// DeleteNode, ...), which neither of those types expose, so this takes a
+// *GraphManager instead -- the same kind of signature adjustment
+// CreateIndex/DropIndex made to support composite indexes (see
+// property_index.go).
+func (wm *WALManager) Recover(graph *GraphManager) error {
+	frames, err := readAllWALFrames(wm.path)
+	if err != nil {
+		return fmt.Errorf("failed to read WAL for recovery: %v", err)
+	}
+
+	committed := make(map[int64]bool)
+	for _, f := range frames {
+		if f.Type == walRecCommit {
+			committed[f.TxnID] = true
+		}
+	}
+
+	for i := len(frames) - 1; i >= 0; i-- {
+		frame := frames[i]
+		if committed[frame.TxnID] {
+			continue
+		}
+		if err := undoWALFrame(frame, graph); err != nil {
+			return fmt.Errorf("failed to undo uncommitted WAL frame (LSN %d): %v", frame.LSN, err)
+		}
+	}
+	return nil
+}
+
+// undoWALFrame reverses a single insert/update/delete frame against
+// graph. Begin/Commit frames carry no image and need no action.
+func undoWALFrame(frame walFrame, graph *GraphManager) error {
+	switch frame.Type {
+	case walRecInsert:
+		isNode, id, _, _, err := decodeWALPayload(frame.Payload)
+		if err != nil {
+			return err
+		}
+		if isNode {
+			return graph.DeleteNode(id)
+		}
+		return graph.DeleteEdge(id)
+
+	case walRecUpdate:
+		isNode, id, before, _, err := decodeWALPayload(frame.Payload)
+		if err != nil {
+			return err
+		}
+		if isNode {
+			var node Node
+			if err := Deserialize(before, &node); err != nil {
+				return err
+			}
+			return graph.ReplaceNodeProperties(id, node.Properties)
+		}
+		var edge Edge
+		if err := Deserialize(before, &edge); err != nil {
+			return err
+		}
+		return graph.ReplaceEdgeProperties(id, edge.Properties)
+
+	case walRecDelete:
+		isNode, _, before, _, err := decodeWALPayload(frame.Payload)
+		if err != nil {
+			return err
+		}
+		if isNode {
+			var node Node
+			if err := Deserialize(before, &node); err != nil {
+				return err
+			}
+			return graph.RestoreNode(node)
+		}
+		var edge Edge
+		if err := Deserialize(before, &edge); err != nil {
+			return err
+		}
+		return graph.RestoreEdge(edge)
+	}
+	return nil
+}
+
+// Close fsyncs and closes the durable log file.
+func (wm *WALManager) Close() error {
+	wm.records = nil
+	if wm.file == nil {
+		return nil
+	}
+	if err := wm.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync WAL file on close: %v", err)
+	}
+	return wm.file.Close()
+}
@@ -0,0 +1,236 @@
+package graphdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// walFileMagic/walFileVersion identify a WAL log file's header, the same
+// way StorageManager's magic bytes identify a data file (see storage.go).
+var walFileMagic = [4]byte{'K', 'W', 'A', 'L'}
+
+const walFileVersion uint32 = 1
+const walHeaderSize = 12 // magic(4) + version(4) + reserved(4)
+const walFrameHeaderSize = 21 // LSN(8) + TxnID(8) + Type(1) + Length(4)
+
+// walRecType tags what kind of frame a walFrame carries.
+type walRecType byte
+
+const (
+	walRecBegin walRecType = iota
+	walRecInsert
+	walRecUpdate
+	walRecDelete
+	walRecCommit
+)
+
+// walFrame is one decoded entry from the log: {LSN, TxnID, Type, Payload}.
+// The on-disk framing also carries a Length and a trailing CRC32, which
+// writeWALFrame/readWALFrame handle and callers never see.
+type walFrame struct {
+	LSN     uint64
+	TxnID   int64
+	Type    walRecType
+	Payload []byte
+}
+
+// writeWALHeader writes path's header at the current file position:
+// magic bytes, format version, and a reserved word for future use.
+func writeWALHeader(file *os.File) error {
+	header := make([]byte, walHeaderSize)
+	copy(header[0:4], walFileMagic[:])
+	binary.LittleEndian.PutUint32(header[4:8], walFileVersion)
+	if _, err := file.Write(header); err != nil {
+		return fmt.Errorf("failed to write WAL header: %v", err)
+	}
+	return nil
+}
+
+// openWALFile opens path for durable appends, creating it with a fresh
+// header if it doesn't exist yet. Writes always land at the end of the
+// file (O_APPEND), so readers that seek around the same handle (Checkpoint,
+// recovery scans) never disturb where the next frame gets written.
+func openWALFile(path string) (*os.File, error) {
+	_, statErr := os.Stat(path)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL file: %v", err)
+	}
+	if os.IsNotExist(statErr) {
+		if err := writeWALHeader(file); err != nil {
+			file.Close()
+			return nil, err
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to sync new WAL file: %v", err)
+		}
+	}
+	return file, nil
+}
+
+// writeWALFrame appends frame to file, framed as
+// {LSN, TxnID, Type, Length, Payload, CRC32}.
+func writeWALFrame(file *os.File, frame walFrame) error {
+	buf := make([]byte, walFrameHeaderSize, walFrameHeaderSize+len(frame.Payload)+4)
+	binary.LittleEndian.PutUint64(buf[0:8], frame.LSN)
+	binary.LittleEndian.PutUint64(buf[8:16], uint64(frame.TxnID))
+	buf[16] = byte(frame.Type)
+	binary.LittleEndian.PutUint32(buf[17:21], uint32(len(frame.Payload)))
+	buf = append(buf, frame.Payload...)
+
+	crc := crc32.ChecksumIEEE(buf)
+	crcBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(crcBytes, crc)
+	buf = append(buf, crcBytes...)
+
+	if _, err := file.Write(buf); err != nil {
+		return fmt.Errorf("failed to write WAL frame: %v", err)
+	}
+	return nil
+}
+
+// readWALFrame reads and CRC-validates the next frame from r. It returns
+// io.EOF (or another read error) once the log is exhausted, including
+// when the last frame was only partially written by a crash mid-append --
+// callers that scan the whole log (readAllWALFrames, scanNextLSN) treat
+// that the same as a clean end of file rather than a fatal corruption.
+func readWALFrame(r io.Reader) (walFrame, error) {
+	header := make([]byte, walFrameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return walFrame{}, err
+	}
+	lsn := binary.LittleEndian.Uint64(header[0:8])
+	txnID := int64(binary.LittleEndian.Uint64(header[8:16]))
+	recType := walRecType(header[16])
+	length := binary.LittleEndian.Uint32(header[17:21])
+
+	payload := make([]byte, length)
+	if length > 0 {
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return walFrame{}, fmt.Errorf("truncated WAL frame payload: %v", err)
+		}
+	}
+	crcBytes := make([]byte, 4)
+	if _, err := io.ReadFull(r, crcBytes); err != nil {
+		return walFrame{}, fmt.Errorf("truncated WAL frame CRC: %v", err)
+	}
+	wantCRC := binary.LittleEndian.Uint32(crcBytes)
+
+	gotCRC := crc32.ChecksumIEEE(append(header, payload...))
+	if gotCRC != wantCRC {
+		return walFrame{}, fmt.Errorf("WAL frame %d failed CRC check (log truncated or corrupted)", lsn)
+	}
+
+	return walFrame{LSN: lsn, TxnID: txnID, Type: recType, Payload: payload}, nil
+}
+
+// scanNextLSN returns the LSN the next frame written to file should use:
+// one past the highest LSN already in the log, or 1 for a freshly
+// created file. file's position is left just past the last readable
+// frame; callers that append afterward rely on O_APPEND, not this offset.
+func scanNextLSN(file *os.File) (uint64, error) {
+	if _, err := file.Seek(walHeaderSize, 0); err != nil {
+		return 0, fmt.Errorf("failed to seek WAL file: %v", err)
+	}
+	reader := bufio.NewReader(file)
+	var maxLSN uint64
+	for {
+		frame, err := readWALFrame(reader)
+		if err != nil {
+			break
+		}
+		if frame.LSN > maxLSN {
+			maxLSN = frame.LSN
+		}
+	}
+	return maxLSN + 1, nil
+}
+
+// readAllWALFrames reads every frame in the WAL file at path from the
+// start, in LSN order, using its own handle so it never disturbs
+// WALManager's append position.
+func readAllWALFrames(path string) ([]walFrame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL file for recovery: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(walHeaderSize, 0); err != nil {
+		return nil, fmt.Errorf("failed to seek WAL file: %v", err)
+	}
+	reader := bufio.NewReader(file)
+	var frames []walFrame
+	for {
+		frame, err := readWALFrame(reader)
+		if err != nil {
+			break
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// encodeWALPayload packs an insert/update/delete frame's logical fields --
+// which record kind, which ID, and its before/after images (nil when not
+// applicable) -- length-prefixed the same way Serialize packs variable-
+// length fields.
+func encodeWALPayload(isNode bool, id int64, before, after []byte) []byte {
+	buf := make([]byte, 0, 13+len(before)+len(after))
+	if isNode {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	idBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(idBytes, uint64(id))
+	buf = append(buf, idBytes...)
+
+	lenBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(lenBytes, uint32(len(before)))
+	buf = append(buf, lenBytes...)
+	buf = append(buf, before...)
+
+	binary.LittleEndian.PutUint32(lenBytes, uint32(len(after)))
+	buf = append(buf, lenBytes...)
+	buf = append(buf, after...)
+	return buf
+}
+
+// decodeWALPayload reverses encodeWALPayload.
+func decodeWALPayload(data []byte) (isNode bool, id int64, before, after []byte, err error) {
+	if len(data) < 13 {
+		return false, 0, nil, nil, fmt.Errorf("WAL payload too short")
+	}
+	isNode = data[0] == 1
+	id = int64(binary.LittleEndian.Uint64(data[1:9]))
+	offset := 9
+
+	beforeLen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+beforeLen > len(data) {
+		return false, 0, nil, nil, fmt.Errorf("WAL payload before-image truncated")
+	}
+	if beforeLen > 0 {
+		before = data[offset : offset+beforeLen]
+	}
+	offset += beforeLen
+
+	if offset+4 > len(data) {
+		return false, 0, nil, nil, fmt.Errorf("WAL payload missing after-length")
+	}
+	afterLen := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+afterLen > len(data) {
+		return false, 0, nil, nil, fmt.Errorf("WAL payload after-image truncated")
+	}
+	if afterLen > 0 {
+		after = data[offset : offset+afterLen]
+	}
+	return isNode, id, before, after, nil
+}